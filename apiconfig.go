@@ -1,20 +1,38 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"os"
 	"sync/atomic"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/jradziejewski/chirpy/internal/auth"
 	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/jradziejewski/chirpy/internal/mailer"
+	"github.com/jradziejewski/chirpy/internal/moderation"
+	"github.com/jradziejewski/chirpy/internal/ratelimit"
+	"github.com/jradziejewski/chirpy/internal/storage"
 )
 
 type apiConfig struct {
 	fileserverHits atomic.Int32
+	conn           *sql.DB
 	db             *database.Queries
 	platform       string
 	secret         string
 	polkaKey       string
+	media          storage.Storage
+	mailer         mailer.Mailer
+	passwordResets chan string
+
+	passwordResetIPLimiter    *ratelimit.Limiter
+	passwordResetEmailLimiter *ratelimit.Limiter
+
+	moderator           *moderation.Moderator
+	moderationRulesFile string
 }
 
 func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
@@ -24,16 +42,104 @@ func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
 	})
 }
 
-func newApiConfig(db *database.Queries) *apiConfig {
+// authenticate returns the ID of the user whose access token is attached to
+// the request.
+func (cfg *apiConfig) authenticate(r *http.Request) (uuid.UUID, error) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return auth.ValidateJWT(token, cfg.secret)
+}
+
+// requireAdmin authenticates the request and checks that the caller is an
+// admin. On failure it responds itself and returns false.
+func (cfg *apiConfig) requireAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return uuid.UUID{}, false
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 401, "Unauthorized", err)
+		return uuid.UUID{}, false
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve user", err)
+		return uuid.UUID{}, false
+	}
+	if !user.IsAdmin {
+		respondWithError(w, 403, "Forbidden", nil)
+		return uuid.UUID{}, false
+	}
+
+	return userID, true
+}
+
+// requireActiveUser authenticates the request and checks that the caller's
+// account is verified and not suspended. It guards every handler that
+// publishes content or acts on chirps and other users; a suspended user is
+// only left able to manage their own account, blocks and mutes. On failure
+// it responds itself and returns false.
+func (cfg *apiConfig) requireActiveUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return uuid.UUID{}, false
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 401, "Unauthorized", err)
+		return uuid.UUID{}, false
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve user", err)
+		return uuid.UUID{}, false
+	}
+	if user.SuspendedAt.Valid {
+		respondWithError(w, 403, "Account suspended", nil)
+		return uuid.UUID{}, false
+	}
+	if !user.EmailVerifiedAt.Valid {
+		respondWithError(w, 403, "Email address not verified", nil)
+		return uuid.UUID{}, false
+	}
+
+	return userID, true
+}
+
+// viewerID identifies the caller on endpoints that work without
+// authentication. A missing or invalid token yields an anonymous viewer.
+func (cfg *apiConfig) viewerID(r *http.Request) uuid.NullUUID {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: userID, Valid: true}
+}
+
+func newApiConfig(conn *sql.DB, db *database.Queries, media storage.Storage, mail mailer.Mailer) *apiConfig {
 	godotenv.Load()
 	platform := os.Getenv("PLATFORM")
 	secret := os.Getenv("SECRET")
 	polkaKey := os.Getenv("POLKA_KEY")
 	cfg := &apiConfig{}
 	cfg.fileserverHits.Store(0)
+	cfg.conn = conn
 	cfg.db = db
 	cfg.platform = platform
 	cfg.secret = secret
 	cfg.polkaKey = polkaKey
+	cfg.media = media
+	cfg.mailer = mail
+	cfg.passwordResets = make(chan string, passwordResetQueueSize)
+	cfg.passwordResetIPLimiter = ratelimit.New(passwordResetIPLimit, passwordResetLimitPeriod)
+	cfg.passwordResetEmailLimiter = ratelimit.New(passwordResetEmailLimit, passwordResetLimitPeriod)
+	cfg.moderator = moderation.NewModerator()
+	cfg.moderationRulesFile = os.Getenv("MODERATION_RULES_FILE")
 	return cfg
 }
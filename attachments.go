@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/jradziejewski/chirpy/internal/media"
+)
+
+const (
+	maxAttachments     = 4
+	maxAltTextLength   = 1000
+	mediaURLPrefix     = "/media/"
+	maxMultipartMemory = 8 << 20
+	// maxMultipartBytes leaves room for the text fields next to the images.
+	maxMultipartBytes = maxAttachments*media.MaxImageBytes + 1<<20
+)
+
+// attachment is an uploaded image that has been validated and re-encoded
+// but not yet stored.
+type attachment struct {
+	image   media.Processed
+	altText string
+}
+
+// readAttachments processes the "media" files of a multipart chirp. The
+// "alt_text" values are matched to the files by position.
+func readAttachments(form *multipart.Form) ([]attachment, error) {
+	files := form.File["media"]
+	if len(files) > maxAttachments {
+		return nil, fmt.Errorf("A chirp can have at most %d images", maxAttachments)
+	}
+	altTexts := form.Value["alt_text"]
+	if len(altTexts) > len(files) {
+		return nil, errors.New("More alt_text values than images")
+	}
+
+	attachments := make([]attachment, 0, len(files))
+	for i, header := range files {
+		if header.Size > media.MaxImageBytes {
+			return nil, fmt.Errorf("Image %d is larger than %d MB", i+1, media.MaxImageBytes>>20)
+		}
+
+		f, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(f, media.MaxImageBytes+1))
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		processed, err := media.Process(data)
+		if errors.Is(err, media.ErrUnsupportedType) {
+			return nil, fmt.Errorf("Image %d must be a JPEG, PNG or GIF", i+1)
+		}
+		if errors.Is(err, media.ErrTooLarge) {
+			return nil, fmt.Errorf("Image %d is too large", i+1)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Image %d could not be decoded", i+1)
+		}
+
+		altText := ""
+		if i < len(altTexts) {
+			altText = altTexts[i]
+		}
+		if len([]rune(altText)) > maxAltTextLength {
+			return nil, fmt.Errorf("alt_text %d is longer than %d characters", i+1, maxAltTextLength)
+		}
+
+		attachments = append(attachments, attachment{image: processed, altText: altText})
+	}
+
+	return attachments, nil
+}
+
+// objectName derives a content-addressed storage name, so identical uploads
+// share a single file.
+func objectName(img media.Image) string {
+	sum := sha256.Sum256(img.Data)
+	return hex.EncodeToString(sum[:]) + img.Ext
+}
+
+// saveAttachments writes the images to storage and records them against the
+// chirp. Files are written before the rows, so a failed transaction can only
+// leave an unreferenced file behind, never a row without a file.
+func (cfg *apiConfig) saveAttachments(ctx context.Context, q *database.Queries, chirp database.Chirp, attachments []attachment) error {
+	for i, a := range attachments {
+		fileName := objectName(a.image.Original)
+		err := cfg.media.Put(ctx, fileName, bytes.NewReader(a.image.Original.Data))
+		if err != nil {
+			return err
+		}
+
+		thumbnailName := objectName(a.image.Thumbnail)
+		err = cfg.media.Put(ctx, thumbnailName, bytes.NewReader(a.image.Thumbnail.Data))
+		if err != nil {
+			return err
+		}
+
+		err = q.CreateChirpAttachment(ctx, database.CreateChirpAttachmentParams{
+			ID:              uuid.New(),
+			ChirpID:         chirp.ID,
+			Position:        int32(i),
+			FileName:        fileName,
+			ThumbnailName:   thumbnailName,
+			ContentType:     a.image.Original.ContentType,
+			Width:           int32(a.image.Original.Width),
+			Height:          int32(a.image.Original.Height),
+			ThumbnailWidth:  int32(a.image.Thumbnail.Width),
+			ThumbnailHeight: int32(a.image.Thumbnail.Height),
+			AltText:         a.altText,
+			CreatedAt:       time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteUnreferencedMedia removes the named objects from storage unless an
+// attachment still uses them. Objects are content-addressed, so the same
+// image uploaded by someone else shares a name and must be kept.
+func (cfg *apiConfig) deleteUnreferencedMedia(ctx context.Context, names []string) error {
+	var errs []error
+	for _, name := range names {
+		refs, err := cfg.db.CountMediaObjectReferences(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if refs > 0 {
+			continue
+		}
+		err = cfg.media.Delete(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+var (
+	errParentNotFound = errors.New("Chirp being replied to does not exist")
+	errQuotedNotFound = errors.New("Quoted chirp does not exist")
+)
+
+// Who can read a chirp. The rules themselves live in the chirp_visible_to
+// SQL function so every query applies them the same way.
+const (
+	visibilityPublic    = "public"
+	visibilityFollowers = "followers"
+	visibilityMentioned = "mentioned"
+)
+
+// validateVisibility defaults an empty visibility to public.
+func validateVisibility(visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return visibilityPublic, nil
+	case visibilityPublic, visibilityFollowers, visibilityMentioned:
+		return visibility, nil
+	}
+	return "", errors.New("visibility must be one of public, followers or mentioned")
+}
+
+// newChirp is a chirp about to be published. Body and Status must come from
+// cleanChirpBody.
+type newChirp struct {
+	UserID     uuid.UUID
+	Body       string
+	InReplyTo  uuid.NullUUID
+	QuoteOf    uuid.NullUUID
+	Visibility string
+	Status     string
+	Poll       *newPoll
+}
+
+type chirpReferences struct {
+	InReplyTo uuid.NullUUID
+	RootID    uuid.NullUUID
+	QuoteOf   uuid.NullUUID
+}
+
+// resolveChirpReferences checks that the replied-to and quoted chirps exist
+// and that userID can see them. A reply inherits the root of its parent, and
+// quoting a rechirp quotes the chirp it re-shares.
+func resolveChirpReferences(ctx context.Context, q *database.Queries, userID uuid.UUID, inReplyTo, quoteOf uuid.NullUUID) (chirpReferences, error) {
+	refs := chirpReferences{}
+
+	if inReplyTo.Valid {
+		parent, err := q.GetVisibleChirp(ctx, database.GetVisibleChirpParams{
+			ID:       inReplyTo.UUID,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return chirpReferences{}, errParentNotFound
+		}
+		if err != nil {
+			return chirpReferences{}, err
+		}
+		refs.InReplyTo = uuid.NullUUID{UUID: parent.ID, Valid: true}
+		refs.RootID = parent.RootID
+		if !refs.RootID.Valid {
+			refs.RootID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+		}
+	}
+
+	if quoteOf.Valid {
+		quoted, err := q.GetVisibleChirp(ctx, database.GetVisibleChirpParams{
+			ID:       quoteOf.UUID,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return chirpReferences{}, errQuotedNotFound
+		}
+		if err != nil {
+			return chirpReferences{}, err
+		}
+		refs.QuoteOf = uuid.NullUUID{UUID: quoted.ID, Valid: true}
+		if quoted.RechirpOf.Valid {
+			refs.QuoteOf = quoted.RechirpOf
+		}
+	}
+
+	return refs, nil
+}
+
+// createChirp publishes a chirp, indexes its hashtags and mentions and
+// attaches its poll, if any. It expects to run inside a transaction so a
+// chirp is never left half created.
+func createChirp(ctx context.Context, q *database.Queries, in newChirp) (database.Chirp, error) {
+	refs, err := resolveChirpReferences(ctx, q, in.UserID, in.InReplyTo, in.QuoteOf)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	now := time.Now().UTC()
+	chirp, err := q.CreateChirp(ctx, database.CreateChirpParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Body:       in.Body,
+		UserID:     in.UserID,
+		InReplyTo:  refs.InReplyTo,
+		RootID:     refs.RootID,
+		QuoteOf:    refs.QuoteOf,
+		IsQuote:    refs.QuoteOf.Valid,
+		Visibility: in.Visibility,
+		Status:     in.Status,
+	})
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	err = indexChirp(ctx, q, chirp)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	if in.Poll != nil {
+		err = createPoll(ctx, q, chirp, *in.Poll)
+		if err != nil {
+			return database.Chirp{}, err
+		}
+	}
+
+	return chirp, nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/auth"
+)
+
+// handlerDeleteAccount deletes the caller's account and, through the
+// database's cascades, everything they own. The password is asked for again
+// so a stolen access token alone cannot destroy an account. Uploaded images
+// are removed from storage too, except those another attachment still uses.
+func (cfg *apiConfig) handlerDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	type parameters struct {
+		Password string `json:"password"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	err = auth.CheckPasswordHash(params.Password, user.HashedPassword.String)
+	if err != nil {
+		respondWithError(w, 403, "Wrong password", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Could not delete account", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	objects, err := qtx.GetUserMediaObjects(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, 500, "Could not delete account", err)
+		return
+	}
+
+	_, err = qtx.DeleteUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, 500, "Could not delete account", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Could not delete account", err)
+		return
+	}
+
+	// The account is gone either way; a file that could not be removed is
+	// logged rather than failing the request.
+	err = cfg.deleteUnreferencedMedia(r.Context(), objects)
+	if err != nil {
+		log.Printf("Error deleting media of user %s: %s", user.ID, err)
+	}
+
+	w.WriteHeader(204)
+}
+
+type exportProfile struct {
+	ID              uuid.UUID     `json:"id"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	Email           string        `json:"email"`
+	EmailVerifiedAt *time.Time    `json:"email_verified_at"`
+	Username        string        `json:"username"`
+	DisplayName     string        `json:"display_name"`
+	Bio             string        `json:"bio"`
+	IsChirpyRed     bool          `json:"is_chirpy_red"`
+	PinnedChirpID   uuid.NullUUID `json:"pinned_chirp_id"`
+	SuspendedAt     *time.Time    `json:"suspended_at"`
+	TotpEnabledAt   *time.Time    `json:"two_factor_enabled_at"`
+}
+
+type exportChirp struct {
+	ID         uuid.UUID     `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Body       string        `json:"body"`
+	InReplyTo  uuid.NullUUID `json:"in_reply_to"`
+	RootID     uuid.NullUUID `json:"root_id"`
+	RechirpOf  uuid.NullUUID `json:"rechirp_of"`
+	QuoteOf    uuid.NullUUID `json:"quote_of"`
+	Visibility string        `json:"visibility"`
+	Status     string        `json:"status"`
+	DeletedAt  *time.Time    `json:"deleted_at"`
+}
+
+type exportRevision struct {
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+type exportDraft struct {
+	ID         uuid.UUID     `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Body       string        `json:"body"`
+	InReplyTo  uuid.NullUUID `json:"in_reply_to"`
+	QuoteOf    uuid.NullUUID `json:"quote_of"`
+	Visibility string        `json:"visibility"`
+}
+
+type exportScheduledChirp struct {
+	ID         uuid.UUID     `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Body       string        `json:"body"`
+	InReplyTo  uuid.NullUUID `json:"in_reply_to"`
+	QuoteOf    uuid.NullUUID `json:"quote_of"`
+	Visibility string        `json:"visibility"`
+	PublishAt  time.Time     `json:"publish_at"`
+	FailedAt   *time.Time    `json:"failed_at"`
+}
+
+// exportRefreshToken describes a session. The token itself is left out so
+// an export cannot be used to sign in.
+type exportRefreshToken struct {
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// handlerExportAccount streams a ZIP archive with the caller's profile,
+// chirps with their edit history, drafts, scheduled chirps and sessions as
+// JSON files. Everything is read before the first
+// byte is written, so a failed read still gets a proper error response.
+func (cfg *apiConfig) handlerExportAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	chirps, err := cfg.db.GetAllChirpsByUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Could not export chirps", err)
+		return
+	}
+
+	revisions, err := cfg.db.GetChirpRevisionsByUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Could not export chirps", err)
+		return
+	}
+
+	drafts, err := cfg.db.GetAllDraftsByUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Could not export drafts", err)
+		return
+	}
+
+	scheduled, err := cfg.db.GetAllScheduledChirpsByUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Could not export scheduled chirps", err)
+		return
+	}
+
+	refreshTokens, err := cfg.db.GetUserRefreshTokens(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Could not export sessions", err)
+		return
+	}
+
+	profile := exportProfile{
+		ID:              user.ID,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		Email:           user.Email,
+		EmailVerifiedAt: nullTimePtr(user.EmailVerifiedAt),
+		Username:        user.Username.String,
+		DisplayName:     user.DisplayName,
+		Bio:             user.Bio,
+		IsChirpyRed:     user.IsChirpyRed.Bool,
+		PinnedChirpID:   user.PinnedChirpID,
+		SuspendedAt:     nullTimePtr(user.SuspendedAt),
+		TotpEnabledAt:   nullTimePtr(user.TotpEnabledAt),
+	}
+
+	exportedChirps := make([]exportChirp, 0, len(chirps))
+	for _, chirp := range chirps {
+		exportedChirps = append(exportedChirps, exportChirp{
+			ID:         chirp.ID,
+			CreatedAt:  chirp.CreatedAt,
+			UpdatedAt:  chirp.UpdatedAt,
+			Body:       chirp.Body,
+			InReplyTo:  chirp.InReplyTo,
+			RootID:     chirp.RootID,
+			RechirpOf:  chirp.RechirpOf,
+			QuoteOf:    chirp.QuoteOf,
+			Visibility: chirp.Visibility,
+			Status:     chirp.Status,
+			DeletedAt:  nullTimePtr(chirp.DeletedAt),
+		})
+	}
+
+	exportedRevisions := make([]exportRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		exportedRevisions = append(exportedRevisions, exportRevision{
+			ChirpID:    revision.ChirpID,
+			Body:       revision.Body,
+			CreatedAt:  revision.CreatedAt,
+			ReplacedAt: revision.ReplacedAt,
+		})
+	}
+
+	exportedDrafts := make([]exportDraft, 0, len(drafts))
+	for _, draft := range drafts {
+		exportedDrafts = append(exportedDrafts, exportDraft{
+			ID:         draft.ID,
+			CreatedAt:  draft.CreatedAt,
+			UpdatedAt:  draft.UpdatedAt,
+			Body:       draft.Body,
+			InReplyTo:  draft.InReplyTo,
+			QuoteOf:    draft.QuoteOf,
+			Visibility: draft.Visibility,
+		})
+	}
+
+	exportedScheduled := make([]exportScheduledChirp, 0, len(scheduled))
+	for _, chirp := range scheduled {
+		exportedScheduled = append(exportedScheduled, exportScheduledChirp{
+			ID:         chirp.ID,
+			CreatedAt:  chirp.CreatedAt,
+			UpdatedAt:  chirp.UpdatedAt,
+			Body:       chirp.Body,
+			InReplyTo:  chirp.InReplyTo,
+			QuoteOf:    chirp.QuoteOf,
+			Visibility: chirp.Visibility,
+			PublishAt:  chirp.PublishAt,
+			FailedAt:   nullTimePtr(chirp.FailedAt),
+		})
+	}
+
+	exportedTokens := make([]exportRefreshToken, 0, len(refreshTokens))
+	for _, token := range refreshTokens {
+		exportedTokens = append(exportedTokens, exportRefreshToken{
+			CreatedAt: token.CreatedAt,
+			UpdatedAt: token.UpdatedAt,
+			ExpiresAt: token.ExpiresAt,
+			RevokedAt: nullTimePtr(token.RevokedAt),
+		})
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{name: "profile.json", data: profile},
+		{name: "chirps.json", data: exportedChirps},
+		{name: "chirp_revisions.json", data: exportedRevisions},
+		{name: "drafts.json", data: exportedDrafts},
+		{name: "scheduled_chirps.json", data: exportedScheduled},
+		{name: "refresh_tokens.json", data: exportedTokens},
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="chirpy-export-%s.zip"`, time.Now().UTC().Format("2006-01-02")))
+	w.WriteHeader(200)
+
+	// From here on the status is sent, so failures can only be logged and
+	// leave the client with a truncated archive.
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			log.Printf("Error writing export: %s", err)
+			return
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(file.data)
+		if err != nil {
+			log.Printf("Error writing export: %s", err)
+			return
+		}
+	}
+	err = archive.Close()
+	if err != nil {
+		log.Printf("Error writing export: %s", err)
+	}
+}
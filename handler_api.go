@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"log"
+	"mime"
 	"net/http"
-	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,16 +18,270 @@ import (
 // Chirps
 
 type ChirpResponse struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID        uuid.UUID           `json:"id"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+	Body      string              `json:"body"`
+	UserID    uuid.UUID           `json:"user_id"`
+	Author    *PublicUserResponse `json:"author,omitempty"`
+	InReplyTo uuid.NullUUID       `json:"in_reply_to"`
+	RootID    uuid.NullUUID       `json:"root_id"`
+	LikeCount int64               `json:"like_count"`
+	LikedByMe *bool               `json:"liked_by_me,omitempty"`
+
+	Visibility string `json:"visibility"`
+	Status     string `json:"status"`
+
+	BookmarkedByMe *bool `json:"bookmarked_by_me,omitempty"`
+	Pinned         bool  `json:"pinned,omitempty"`
+	Deleted        bool  `json:"deleted,omitempty"`
+
+	Mentions []MentionEntity `json:"mentions"`
+	Media    []MediaResponse `json:"media"`
+	Poll     *PollResponse   `json:"poll,omitempty"`
+
+	RechirpOf          *ChirpResponse `json:"rechirp_of,omitempty"`
+	QuotedChirp        *ChirpResponse `json:"quoted_chirp,omitempty"`
+	QuotedChirpDeleted bool           `json:"quoted_chirp_deleted,omitempty"`
 }
 
+// MentionEntity locates an @mention inside a chirp body. Offset and Length
+// count Unicode code points and include the leading @.
+type MentionEntity struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Offset   int32     `json:"offset"`
+	Length   int32     `json:"length"`
+}
+
+// newTombstoneResponse stands in for a deleted chirp. It keeps only what is
+// needed to place the chirp in a thread.
+func newTombstoneResponse(chirp database.Chirp) ChirpResponse {
+	return ChirpResponse{
+		ID:        chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		InReplyTo: chirp.InReplyTo,
+		RootID:    chirp.RootID,
+		Deleted:   true,
+		Mentions:  []MentionEntity{},
+		Media:     []MediaResponse{},
+	}
+}
+
+func newChirpResponse(chirp database.Chirp) ChirpResponse {
+	return ChirpResponse{
+		ID:         chirp.ID,
+		CreatedAt:  chirp.CreatedAt,
+		UpdatedAt:  chirp.UpdatedAt,
+		Body:       chirp.Body,
+		UserID:     chirp.UserID,
+		InReplyTo:  chirp.InReplyTo,
+		RootID:     chirp.RootID,
+		Visibility: chirp.Visibility,
+		Status:     chirp.Status,
+		Mentions:   []MentionEntity{},
+		Media:      []MediaResponse{},
+	}
+}
+
+// buildChirpResponses turns chirps into responses, loading per-chirp
+// aggregates in batches so a page costs a fixed number of queries. viewerID
+// is set when the request carries a valid access token.
+//
+// Rechirped and quoted chirps are inlined one level deep, if the viewer can
+// see them. A quote whose original has been deleted is marked with
+// quoted_chirp_deleted instead.
+func (cfg *apiConfig) buildChirpResponses(ctx context.Context, chirps []database.Chirp, viewerID uuid.NullUUID) ([]ChirpResponse, error) {
+	responses, err := cfg.chirpResponsesWithStats(ctx, chirps, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var referencedIDs []uuid.UUID
+	for _, chirp := range chirps {
+		if chirp.RechirpOf.Valid {
+			referencedIDs = append(referencedIDs, chirp.RechirpOf.UUID)
+		}
+		if chirp.QuoteOf.Valid {
+			referencedIDs = append(referencedIDs, chirp.QuoteOf.UUID)
+		}
+	}
+
+	referenced := make(map[uuid.UUID]ChirpResponse, len(referencedIDs))
+	deleted := map[uuid.UUID]bool{}
+	if len(referencedIDs) > 0 {
+		referencedChirps, err := cfg.db.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+			Ids:      referencedIDs,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		live := make([]database.Chirp, 0, len(referencedChirps))
+		for _, chirp := range referencedChirps {
+			if chirp.DeletedAt.Valid {
+				deleted[chirp.ID] = true
+				continue
+			}
+			live = append(live, chirp)
+		}
+		referencedResponses, err := cfg.chirpResponsesWithStats(ctx, live, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		for i, chirp := range live {
+			referenced[chirp.ID] = referencedResponses[i]
+		}
+	}
+
+	for i, chirp := range chirps {
+		if chirp.RechirpOf.Valid {
+			if original, ok := referenced[chirp.RechirpOf.UUID]; ok {
+				responses[i].RechirpOf = &original
+			}
+		}
+		if chirp.IsQuote {
+			if !chirp.QuoteOf.Valid || deleted[chirp.QuoteOf.UUID] {
+				responses[i].QuotedChirpDeleted = true
+			} else if quoted, ok := referenced[chirp.QuoteOf.UUID]; ok {
+				responses[i].QuotedChirp = &quoted
+			}
+		}
+	}
+
+	return responses, nil
+}
+
+func (cfg *apiConfig) chirpResponsesWithStats(ctx context.Context, chirps []database.Chirp, viewerID uuid.NullUUID) ([]ChirpResponse, error) {
+	responses := make([]ChirpResponse, 0, len(chirps))
+	if len(chirps) == 0 {
+		return responses, nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+
+	likeStats, err := cfg.db.GetChirpLikeStats(ctx, database.GetChirpLikeStatsParams{
+		ViewerID: viewerID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	likes := make(map[uuid.UUID]database.GetChirpLikeStatsRow, len(likeStats))
+	for _, stat := range likeStats {
+		likes[stat.ChirpID] = stat
+	}
+
+	authorIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		authorIDs = append(authorIDs, chirp.UserID)
+	}
+	authorRows, err := cfg.db.GetUsersByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := cfg.visiblePins(ctx, authorRows, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	authors := make(map[uuid.UUID]PublicUserResponse, len(authorRows))
+	for _, author := range authorRows {
+		authors[author.ID] = newPublicUserResponse(author, pins)
+	}
+
+	mentionRows, err := cfg.db.GetChirpMentions(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	mentions := make(map[uuid.UUID][]MentionEntity, len(mentionRows))
+	for _, row := range mentionRows {
+		mentions[row.ChirpID] = append(mentions[row.ChirpID], MentionEntity{
+			UserID:   row.UserID,
+			Username: row.Username.String,
+			Offset:   row.StartOffset,
+			Length:   row.Length,
+		})
+	}
+
+	attachmentRows, err := cfg.db.GetChirpAttachments(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	attachments := make(map[uuid.UUID][]MediaResponse, len(attachmentRows))
+	for _, row := range attachmentRows {
+		attachments[row.ChirpID] = append(attachments[row.ChirpID], MediaResponse{
+			URL:             mediaURLPrefix + row.FileName,
+			ThumbnailURL:    mediaURLPrefix + row.ThumbnailName,
+			ContentType:     row.ContentType,
+			Width:           row.Width,
+			Height:          row.Height,
+			ThumbnailWidth:  row.ThumbnailWidth,
+			ThumbnailHeight: row.ThumbnailHeight,
+			AltText:         row.AltText,
+		})
+	}
+
+	polls, err := cfg.loadPolls(ctx, chirpIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bookmarks are private, so they are only ever checked for the viewer.
+	bookmarked := map[uuid.UUID]bool{}
+	if viewerID.Valid {
+		bookmarkedIDs, err := cfg.db.GetBookmarkedChirpIDs(ctx, database.GetBookmarkedChirpIDsParams{
+			UserID:   viewerID.UUID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range bookmarkedIDs {
+			bookmarked[id] = true
+		}
+	}
+
+	for _, chirp := range chirps {
+		resp := newChirpResponse(chirp)
+		if author, ok := authors[chirp.UserID]; ok {
+			resp.Author = &author
+		}
+		stat := likes[chirp.ID]
+		resp.LikeCount = stat.LikeCount
+		if viewerID.Valid {
+			likedByMe := stat.LikedByViewer
+			resp.LikedByMe = &likedByMe
+			bookmarkedByMe := bookmarked[chirp.ID]
+			resp.BookmarkedByMe = &bookmarkedByMe
+		}
+		if chirpMentions, ok := mentions[chirp.ID]; ok {
+			resp.Mentions = chirpMentions
+		}
+		if chirpMedia, ok := attachments[chirp.ID]; ok {
+			resp.Media = chirpMedia
+		}
+		resp.Poll = polls[chirp.ID]
+		responses = append(responses, resp)
+	}
+
+	return responses, nil
+}
+
+func (cfg *apiConfig) buildChirpResponse(ctx context.Context, chirp database.Chirp, viewerID uuid.NullUUID) (ChirpResponse, error) {
+	responses, err := cfg.buildChirpResponses(ctx, []database.Chirp{chirp}, viewerID)
+	if err != nil {
+		return ChirpResponse{}, err
+	}
+	return responses[0], nil
+}
+
+// handlerGetChirp reports a chirp the caller may not see as missing rather
+// than forbidden, so its existence is not leaked.
 func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
 	chirpID := r.PathValue("chirpID")
-	resp := ChirpResponse{}
 
 	parsedChirpID, err := uuid.Parse(chirpID)
 	if err != nil {
@@ -32,139 +289,491 @@ func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	chirp, err := cfg.db.GetChirp(r.Context(), parsedChirpID)
+	viewerID := cfg.viewerID(r)
+	chirp, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+		ID:       parsedChirpID,
+		ViewerID: viewerID,
+	})
 	if err != nil {
 		respondWithError(w, 404, "Could not retrieve chirp", err)
 		return
 	}
-	resp.ID = chirp.ID
-	resp.CreatedAt = chirp.CreatedAt
-	resp.UpdatedAt = chirp.UpdatedAt
-	resp.Body = chirp.Body
-	resp.UserID = chirp.UserID
+
+	resp, err := cfg.buildChirpResponse(r.Context(), chirp, viewerID)
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve chirp", err)
+		return
+	}
 
 	respondWithJson(w, 200, resp)
 }
 
+type ChirpPage struct {
+	Chirps     []ChirpResponse `json:"chirps"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
 func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
-	var userID interface{}
+	userID := uuid.NullUUID{}
 	authorID := r.URL.Query().Get("author_id")
 	sortParam := r.URL.Query().Get("sort")
 	if authorID != "" {
 		parsed, err := uuid.Parse(authorID)
 		if err != nil {
-			respondWithError(w, 500, "Could not parse author_id", err)
+			respondWithError(w, 400, "Could not parse author_id", err)
 			return
 		}
-		userID = parsed
+		userID = uuid.NullUUID{UUID: parsed, Valid: true}
 	}
-	chirps, err := cfg.db.GetChirps(r.Context(), userID)
+	if sortParam != "" && sortParam != "asc" && sortParam != "desc" {
+		respondWithError(w, 400, "sort must be either asc or desc", nil)
+		return
+	}
+
+	page, err := parsePageParams(r)
 	if err != nil {
-		respondWithError(w, 500, "Could not retrieve chirps", err)
+		respondWithError(w, 400, err.Error(), err)
 		return
 	}
 
-	var chirpResponses []ChirpResponse
-	for _, chirp := range chirps {
-		chirpResponses = append(chirpResponses, ChirpResponse{
-			ID:        chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body:      chirp.Body,
-			UserID:    chirp.UserID,
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+	viewerID := cfg.viewerID(r)
+
+	// An author feed starts with the author's pinned chirp. The query leaves
+	// it out of every page so it is only listed once, and on the first page
+	// it takes one of the slots. A page of one has no room for it.
+	pinnedID := uuid.NullUUID{}
+	if userID.Valid {
+		author, err := cfg.db.GetUser(r.Context(), userID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, 500, "Could not retrieve chirps", err)
+			return
+		}
+		pinnedID = author.PinnedChirpID
+	}
+	var pinned *database.Chirp
+	if pinnedID.Valid && page.Cursor == nil && page.Limit > 1 {
+		// A pinned chirp that cannot be found has been deleted or is hidden
+		// from the viewer.
+		chirp, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+			ID:       pinnedID.UUID,
+			ViewerID: viewerID,
 		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, 500, "Could not retrieve chirps", err)
+			return
+		}
+		if err == nil {
+			pinned = &chirp
+		}
+	}
+	limit := page.Limit
+	if pinned != nil {
+		limit--
 	}
 
+	// One extra row tells us whether there is a next page.
+	var chirps []database.Chirp
 	if sortParam == "desc" {
-		sort.Slice(chirpResponses, func(i, j int) bool {
-			return chirpResponses[i].CreatedAt.After(chirpResponses[j].CreatedAt)
+		chirps, err = cfg.db.GetChirpsDesc(r.Context(), database.GetChirpsDescParams{
+			UserID:          userID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			ViewerID:        viewerID,
+			ExcludeID:       pinnedID,
+			Limit:           limit + 1,
 		})
 	} else {
-		sort.Slice(chirpResponses, func(i, j int) bool {
-			return chirpResponses[j].CreatedAt.After(chirpResponses[i].CreatedAt)
+		chirps, err = cfg.db.GetChirpsAsc(r.Context(), database.GetChirpsAscParams{
+			UserID:          userID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			ViewerID:        viewerID,
+			ExcludeID:       pinnedID,
+			Limit:           limit + 1,
 		})
 	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve chirps", err)
+		return
+	}
 
-	respondWithJson(w, 200, chirpResponses)
+	resp := ChirpPage{}
+	if len(chirps) > int(limit) {
+		chirps = chirps[:limit]
+		last := chirps[len(chirps)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if pinned != nil {
+		chirps = append([]database.Chirp{*pinned}, chirps...)
+	}
+
+	resp.Chirps, err = cfg.buildChirpResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve chirps", err)
+		return
+	}
+	if pinned != nil {
+		resp.Chirps[0].Pinned = true
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
 }
 
 func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request) {
-	token, err := auth.GetBearerToken(r.Header)
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Body       string          `json:"body"`
+		InReplyTo  uuid.NullUUID   `json:"in_reply_to"`
+		QuoteOf    uuid.NullUUID   `json:"quote_of"`
+		Visibility string          `json:"visibility"`
+		PublishAt  *time.Time      `json:"publish_at"`
+		Poll       *pollParameters `json:"poll"`
+	}
+	params := parameters{}
+	var attachments []attachment
+	var err error
+
+	// Images are uploaded as multipart/form-data with the same fields as
+	// the JSON body, plus "media" files and matching "alt_text" values.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		r.Body = http.MaxBytesReader(w, r.Body, maxMultipartBytes)
+		err = r.ParseMultipartForm(maxMultipartMemory)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respondWithError(w, 413, "Upload too large", err)
+				return
+			}
+			respondWithError(w, 400, "Error decoding form", err)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		params.Body = r.FormValue("body")
+		params.InReplyTo, err = parseOptionalUUID(r.FormValue("in_reply_to"))
+		if err != nil {
+			respondWithError(w, 400, "Could not parse in_reply_to", err)
+			return
+		}
+		params.QuoteOf, err = parseOptionalUUID(r.FormValue("quote_of"))
+		if err != nil {
+			respondWithError(w, 400, "Could not parse quote_of", err)
+			return
+		}
+		params.Visibility = r.FormValue("visibility")
+		if publishAt := r.FormValue("publish_at"); publishAt != "" {
+			parsed, err := time.Parse(time.RFC3339, publishAt)
+			if err != nil {
+				respondWithError(w, 400, "publish_at must be an RFC 3339 timestamp", err)
+				return
+			}
+			params.PublishAt = &parsed
+		}
+
+		attachments, err = readAttachments(r.MultipartForm)
+		if err != nil {
+			respondWithError(w, 400, err.Error(), err)
+			return
+		}
+	} else {
+		decoder := json.NewDecoder(r.Body)
+		err = decoder.Decode(&params)
+		if err != nil {
+			respondWithError(w, 500, "Error decoding JSON", err)
+			return
+		}
+	}
+
+	// A chirp may consist of images alone.
+	cleanBody := ""
+	status := chirpStatusPublished
+	if params.Body != "" || len(attachments) == 0 {
+		cleanBody, status, err = cfg.cleanChirpBody(params.Body)
+		if errors.Is(err, errChirpRejected) {
+			respondWithError(w, 422, err.Error(), err)
+			return
+		}
+		if err != nil {
+			respondWithError(w, 400, err.Error(), nil)
+			return
+		}
+	}
+
+	visibility, err := validateVisibility(params.Visibility)
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized", err)
+		respondWithError(w, 400, err.Error(), nil)
 		return
 	}
 
-	userID, err := auth.ValidateJWT(token, cfg.secret)
+	var poll *newPoll
+	if params.Poll != nil {
+		if len(attachments) > 0 {
+			respondWithError(w, 400, "A chirp cannot have both images and a poll", nil)
+			return
+		}
+		if params.PublishAt != nil {
+			respondWithError(w, 400, "Scheduled chirps cannot include polls", nil)
+			return
+		}
+		validated, err := validatePoll(*params.Poll, time.Now().UTC())
+		if err != nil {
+			respondWithError(w, 400, err.Error(), err)
+			return
+		}
+		validated.Options, status, err = cfg.cleanPollOptions(validated.Options, status)
+		if errors.Is(err, errChirpRejected) {
+			respondWithError(w, 422, err.Error(), err)
+			return
+		}
+		if err != nil {
+			respondWithError(w, 400, err.Error(), nil)
+			return
+		}
+		poll = &validated
+	}
+
+	if params.PublishAt != nil {
+		if len(attachments) > 0 {
+			respondWithError(w, 400, "Scheduled chirps cannot include images", nil)
+			return
+		}
+		cfg.scheduleChirp(w, r, newChirp{
+			UserID:     userID,
+			Body:       cleanBody,
+			InReplyTo:  params.InReplyTo,
+			QuoteOf:    params.QuoteOf,
+			Visibility: visibility,
+			Status:     status,
+		}, *params.PublishAt)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized", err)
+		respondWithError(w, 500, "Error creating chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := createChirp(r.Context(), qtx, newChirp{
+		UserID:     userID,
+		Body:       cleanBody,
+		InReplyTo:  params.InReplyTo,
+		QuoteOf:    params.QuoteOf,
+		Visibility: visibility,
+		Status:     status,
+		Poll:       poll,
+	})
+	if errors.Is(err, errParentNotFound) || errors.Is(err, errQuotedNotFound) {
+		respondWithError(w, 404, err.Error(), err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error creating chirp", err)
+		return
+	}
+
+	err = cfg.saveAttachments(r.Context(), qtx, chirp, attachments)
+	if err != nil {
+		respondWithError(w, 500, "Error saving media", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error creating chirp", err)
+		return
+	}
+
+	resp, err := cfg.buildChirpResponse(r.Context(), chirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, "Error creating chirp", err)
+		return
+	}
+
+	respondWithJson(w, 201, resp)
+}
+
+func (cfg *apiConfig) handlerUpdateChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
 		return
 	}
 
 	type parameters struct {
 		Body string `json:"body"`
 	}
-	type returnVals struct {
-		ID        uuid.UUID `json:"id"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		Body      string    `json:"body"`
-		UserID    uuid.UUID `json:"user_id"`
-	}
-	resp := returnVals{}
 	params := parameters{}
 
 	decoder := json.NewDecoder(r.Body)
 	err = decoder.Decode(&params)
 	if err != nil {
-		respondWithError(w, 500, "Error decoding JSON", err)
+		respondWithError(w, 400, "Error decoding JSON", err)
 		return
 	}
 
-	if len(params.Body) > 140 {
-		respondWithError(w, 400, "Body too long", nil)
+	cleanBody, status, err := cfg.cleanChirpBody(params.Body)
+	if errors.Is(err, errChirpRejected) {
+		respondWithError(w, 422, err.Error(), err)
 		return
-	} else if len(params.Body) == 0 {
-		respondWithError(w, 400, "Empty body", nil)
+	}
+	if err != nil {
+		respondWithError(w, 400, err.Error(), nil)
 		return
 	}
 
-	cleanBody := replaceProfane(params.Body)
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Error updating chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
 
-	now := time.Now().UTC()
-	chirpParams := database.CreateChirpParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		Body:      cleanBody,
-		UserID:    userID,
+	chirp, err := qtx.GetChirpForUpdate(r.Context(), parsedChirpID)
+	if err != nil {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+	if chirp.DeletedAt.Valid {
+		respondWithError(w, 404, "Could not retrieve chirp", nil)
+		return
+	}
+	if chirp.UserID != userID {
+		respondWithError(w, 403, "Forbidden", nil)
+		return
+	}
+	if chirp.RechirpOf.Valid {
+		respondWithError(w, 400, "Rechirps cannot be edited", nil)
+		return
+	}
+	if chirp.Body != cleanBody {
+		now := time.Now().UTC()
+		_, err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+			ID:         uuid.New(),
+			ChirpID:    chirp.ID,
+			Body:       chirp.Body,
+			CreatedAt:  chirp.UpdatedAt,
+			ReplacedAt: now,
+		})
+		if err != nil {
+			respondWithError(w, 500, "Error saving chirp revision", err)
+			return
+		}
+
+		chirp, err = qtx.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+			Body:      cleanBody,
+			UpdatedAt: now,
+			ID:        chirp.ID,
+		})
+		if err != nil {
+			respondWithError(w, 500, "Error updating chirp", err)
+			return
+		}
+
+		err = indexChirp(r.Context(), qtx, chirp)
+		if err != nil {
+			respondWithError(w, 500, "Error indexing chirp", err)
+			return
+		}
+
+		// An edit can get a chirp held, but only a moderator can release it.
+		if status == chirpStatusHeld && chirp.Status != chirpStatusHeld {
+			err = qtx.HoldChirp(r.Context(), chirp.ID)
+			if err != nil {
+				respondWithError(w, 500, "Error updating chirp", err)
+				return
+			}
+			chirp.Status = chirpStatusHeld
+		}
 	}
 
-	chirp, err := cfg.db.CreateChirp(r.Context(), chirpParams)
+	err = tx.Commit()
 	if err != nil {
-		respondWithError(w, 500, "Error creating chirp", err)
+		respondWithError(w, 500, "Error updating chirp", err)
 		return
 	}
 
-	resp.ID = chirp.ID
-	resp.CreatedAt = chirp.CreatedAt
-	resp.UpdatedAt = chirp.UpdatedAt
-	resp.Body = chirp.Body
-	resp.UserID = chirp.UserID
+	resp, err := cfg.buildChirpResponse(r.Context(), chirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, "Error updating chirp", err)
+		return
+	}
 
-	respondWithJson(w, 201, resp)
+	respondWithJson(w, 200, resp)
 }
 
-func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request) {
-	token, err := auth.GetBearerToken(r.Header)
+type ChirpRevisionResponse struct {
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+func (cfg *apiConfig) handlerGetChirpHistory(w http.ResponseWriter, r *http.Request) {
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized", err)
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
 		return
 	}
 
-	userID, err := auth.ValidateJWT(token, cfg.secret)
+	viewerID := cfg.viewerID(r)
+	chirp, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+		ID:       parsedChirpID,
+		ViewerID: viewerID,
+	})
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized", err)
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+
+	revisions, err := cfg.db.GetChirpRevisions(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve chirp history", err)
+		return
+	}
+
+	type response struct {
+		Chirp     ChirpResponse           `json:"chirp"`
+		Revisions []ChirpRevisionResponse `json:"revisions"`
+	}
+	chirpResp, err := cfg.buildChirpResponse(r.Context(), chirp, viewerID)
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve chirp history", err)
+		return
+	}
+
+	resp := response{
+		Chirp:     chirpResp,
+		Revisions: []ChirpRevisionResponse{},
+	}
+	for _, revision := range revisions {
+		resp.Revisions = append(resp.Revisions, ChirpRevisionResponse{
+			Body:       revision.Body,
+			CreatedAt:  revision.CreatedAt,
+			ReplacedAt: revision.ReplacedAt,
+		})
+	}
+
+	respondWithJson(w, 200, resp)
+}
+
+func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -181,12 +790,55 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, 404, "Could not retrieve chirp", err)
 		return
 	}
+	if chirp.DeletedAt.Valid {
+		respondWithError(w, 404, "Could not retrieve chirp", nil)
+		return
+	}
 	if chirp.UserID != userID {
 		respondWithError(w, 403, "Forbidden", nil)
 		return
 	}
 
-	err = cfg.db.DeleteChirp(r.Context(), parsedChirpID)
+	// A rechirp has no content of its own, so it is removed outright as
+	// handlerUndoRechirp does.
+	if chirp.RechirpOf.Valid {
+		err = cfg.db.DeleteRechirp(r.Context(), database.DeleteRechirpParams{
+			UserID:    userID,
+			RechirpOf: chirp.RechirpOf,
+		})
+		if err != nil {
+			respondWithError(w, 500, "Could not delete chirp", err)
+			return
+		}
+		w.WriteHeader(204)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Could not delete chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// The chirp can be restored until runPurger removes it for good.
+	err = qtx.SoftDeleteChirp(r.Context(), database.SoftDeleteChirpParams{
+		DeletedAt: time.Now().UTC(),
+		ID:        chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not delete chirp", err)
+		return
+	}
+
+	err = qtx.ClearPinnedChirp(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, 500, "Could not delete chirp", err)
+		return
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		respondWithError(w, 500, "Could not delete chirp", err)
 		return
@@ -195,6 +847,64 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(204)
 }
 
+// handlerRestoreChirp undoes a delete within chirpRestoreWindow.
+func (cfg *apiConfig) handlerRestoreChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(r.Context(), parsedChirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve chirp", err)
+		return
+	}
+	if chirp.UserID != userID {
+		respondWithError(w, 403, "Forbidden", nil)
+		return
+	}
+	if !chirp.DeletedAt.Valid {
+		respondWithError(w, 409, "Chirp has not been deleted", nil)
+		return
+	}
+	if time.Since(chirp.DeletedAt.Time) > chirpRestoreWindow {
+		respondWithError(w, 410, "Chirp can no longer be restored", nil)
+		return
+	}
+
+	err = cfg.db.RestoreChirp(r.Context(), database.RestoreChirpParams{
+		ID:        chirp.ID,
+		DeletedAt: chirp.DeletedAt.Time,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, 409, "A chirp with the same body has been posted since", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not restore chirp", err)
+		return
+	}
+	chirp.DeletedAt = sql.NullTime{}
+
+	resp, err := cfg.buildChirpResponse(r.Context(), chirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, "Could not restore chirp", err)
+		return
+	}
+
+	respondWithJson(w, 200, resp)
+}
+
 // Users
 
 type UserResponse struct {
@@ -203,12 +913,81 @@ type UserResponse struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 	Email       string    `json:"email"`
 	IsChirpyRed bool      `json:"is_chirpy_red"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+
+	EmailVerified    bool `json:"email_verified"`
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+}
+
+// PublicUserResponse is the view of a user anyone may see. It must never
+// carry the email address or other private fields.
+type PublicUserResponse struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+
+	// PinnedChirpID is left out unless the viewer may read the pinned
+	// chirp, so a pin does not reveal that a restricted chirp exists.
+	PinnedChirpID *uuid.UUID `json:"pinned_chirp_id,omitempty"`
+}
+
+// newPublicUserResponse builds the view of user for a viewer. visiblePins
+// holds the pinned chirps that viewer may read, as found by visiblePins.
+func newPublicUserResponse(user database.User, visiblePins map[uuid.UUID]bool) PublicUserResponse {
+	resp := PublicUserResponse{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		Username:    user.Username.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		IsChirpyRed: user.IsChirpyRed.Bool,
+	}
+	if user.PinnedChirpID.Valid && visiblePins[user.PinnedChirpID.UUID] {
+		pinned := user.PinnedChirpID.UUID
+		resp.PinnedChirpID = &pinned
+	}
+	return resp
+}
+
+// visiblePins returns the set of chirps pinned by users that viewerID may
+// read.
+func (cfg *apiConfig) visiblePins(ctx context.Context, users []database.User, viewerID uuid.NullUUID) (map[uuid.UUID]bool, error) {
+	pinIDs := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		if user.PinnedChirpID.Valid {
+			pinIDs = append(pinIDs, user.PinnedChirpID.UUID)
+		}
+	}
+	if len(pinIDs) == 0 {
+		return nil, nil
+	}
+
+	visible, err := cfg.db.GetVisibleChirpIDs(ctx, database.GetVisibleChirpIDsParams{
+		Ids:      pinIDs,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pins := make(map[uuid.UUID]bool, len(visible))
+	for _, id := range visible {
+		pins[id] = true
+	}
+	return pins, nil
 }
 
 func (cfg *apiConfig) handlerCredentialsChange(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email       string  `json:"email"`
+		Password    string  `json:"password"`
+		Username    *string `json:"username"`
+		DisplayName *string `json:"display_name"`
+		Bio         *string `json:"bio"`
 	}
 	params := parameters{}
 	resp := UserResponse{}
@@ -233,31 +1012,126 @@ func (cfg *apiConfig) handlerCredentialsChange(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	hashedPassword, err := auth.HashPassword(params.Password)
+	// Email and password are replaced together; profile fields that are
+	// left out of the request keep their current value.
+	changeCredentials := params.Email != "" || params.Password != ""
+	if changeCredentials && (params.Email == "" || params.Password == "") {
+		respondWithError(w, 400, "Email and password must be changed together", nil)
+		return
+	}
+	if changeCredentials {
+		err = validateEmail(params.Email)
+		if err != nil {
+			respondWithError(w, 400, err.Error(), err)
+			return
+		}
+	}
+
+	profileParams := database.UpdateProfileParams{ID: userID}
+	if params.Username != nil {
+		err = validateUsername(*params.Username)
+		if err != nil {
+			respondWithError(w, 400, err.Error(), err)
+			return
+		}
+		profileParams.Username = sql.NullString{String: *params.Username, Valid: true}
+	}
+	if params.DisplayName != nil {
+		err = validateDisplayName(*params.DisplayName)
+		if err != nil {
+			respondWithError(w, 400, err.Error(), err)
+			return
+		}
+		profileParams.DisplayName = sql.NullString{String: *params.DisplayName, Valid: true}
+	}
+	if params.Bio != nil {
+		err = validateBio(*params.Bio)
+		if err != nil {
+			respondWithError(w, 400, err.Error(), err)
+			return
+		}
+		profileParams.Bio = sql.NullString{String: *params.Bio, Valid: true}
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
 	if err != nil {
-		respondWithError(w, 500, "An error occurred while hashing password", err)
+		respondWithError(w, 500, "An error occurred while updating credentials", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+	verificationToken := ""
 
-	updateParams := database.UpdateEmailAndPasswordParams{
-		Email: params.Email,
-		HashedPassword: sql.NullString{
-			String: hashedPassword,
-			Valid:  true,
-		},
-		ID: userID,
+	if changeCredentials {
+		hashedPassword, err := auth.HashPassword(params.Password)
+		if err != nil {
+			respondWithError(w, 500, "An error occurred while hashing password", err)
+			return
+		}
+
+		updateParams := database.UpdateEmailAndPasswordParams{
+			Email: params.Email,
+			HashedPassword: sql.NullString{
+				String: hashedPassword,
+				Valid:  true,
+			},
+			ID: userID,
+		}
+
+		updated, err := qtx.UpdateEmailAndPassword(r.Context(), updateParams)
+		if isUniqueViolation(err) {
+			respondWithError(w, 409, "Email already taken", err)
+			return
+		}
+		if err != nil {
+			respondWithError(w, 500, "An error occurred while updating credentials", err)
+			return
+		}
+
+		// A new address has to be verified before the account can post
+		// again.
+		if !updated.EmailVerifiedAt.Valid {
+			verificationToken, err = cfg.createEmailVerification(r.Context(), qtx, updated)
+			if err != nil {
+				respondWithError(w, 500, "An error occurred while updating credentials", err)
+				return
+			}
+		}
 	}
 
-	user, err := cfg.db.UpdateEmailAndPassword(r.Context(), updateParams)
+	user, err := qtx.UpdateProfile(r.Context(), profileParams)
+	if isUniqueViolation(err) {
+		respondWithError(w, 409, "Username already taken", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "An error occurred while updating profile", err)
+		return
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		respondWithError(w, 500, "An error occurred while updating credentials", err)
 		return
 	}
+
+	if verificationToken != "" {
+		err = cfg.sendVerificationEmail(r.Context(), user.Email, verificationToken)
+		if err != nil {
+			log.Printf("Error sending verification email: %s", err)
+		}
+	}
+
 	resp.ID = user.ID
 	resp.CreatedAt = user.CreatedAt
 	resp.UpdatedAt = user.UpdatedAt
 	resp.Email = user.Email
 	resp.IsChirpyRed = user.IsChirpyRed.Bool
+	resp.Username = user.Username.String
+	resp.DisplayName = user.DisplayName
+	resp.Bio = user.Bio
+	resp.EmailVerified = user.EmailVerifiedAt.Valid
+	resp.TwoFactorEnabled = user.TotpEnabledAt.Valid
 
 	respondWithJson(w, 200, resp)
 }
@@ -267,12 +1141,10 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		Email    string `json:"email"`
 		Password string `json:"password"`
 	}
-	type response struct {
-		UserResponse
-		Token        string `json:"token"`
-		RefreshToken string `json:"refresh_token"`
+	type challengeResponse struct {
+		TwoFactorRequired bool   `json:"two_factor_required"`
+		ChallengeToken    string `json:"challenge_token"`
 	}
-	resp := response{}
 	params := parameters{}
 
 	decoder := json.NewDecoder(r.Body)
@@ -294,6 +1166,37 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, 401, "Wrong credentials", err)
 		return
 	}
+	if user.SuspendedAt.Valid {
+		respondWithError(w, 403, "Account suspended", nil)
+		return
+	}
+
+	if user.TotpEnabledAt.Valid {
+		challengeToken, err := cfg.createLoginChallenge(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, 500, "Error creating login challenge", err)
+			return
+		}
+		respondWithJson(w, 200, challengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		})
+		return
+	}
+
+	cfg.respondWithSession(w, r, user)
+}
+
+// respondWithSession issues an access and a refresh token for user and
+// writes them with the user's profile. Callers must have checked the
+// user's credentials.
+func (cfg *apiConfig) respondWithSession(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		UserResponse
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	resp := response{}
 
 	token, err := auth.MakeJWT(user.ID, cfg.secret, time.Hour)
 	if err != nil {
@@ -328,6 +1231,11 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 	resp.CreatedAt = user.CreatedAt
 	resp.UpdatedAt = user.UpdatedAt
 	resp.IsChirpyRed = user.IsChirpyRed.Bool
+	resp.Username = user.Username.String
+	resp.DisplayName = user.DisplayName
+	resp.Bio = user.Bio
+	resp.EmailVerified = user.EmailVerifiedAt.Valid
+	resp.TwoFactorEnabled = user.TotpEnabledAt.Valid
 	resp.Token = token
 	resp.RefreshToken = refreshToken.Token
 
@@ -338,6 +1246,7 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 	type parameters struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Username string `json:"username"`
 	}
 	resp := UserResponse{}
 	params := parameters{}
@@ -350,6 +1259,19 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	err = validateEmail(params.Email)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	if params.Username != "" {
+		err = validateUsername(params.Username)
+		if err != nil {
+			respondWithError(w, 400, err.Error(), err)
+			return
+		}
+	}
+
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, 500, "Error hashing password", err)
@@ -366,22 +1288,79 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 			String: hashedPassword,
 			Valid:  true,
 		},
+		Username: sql.NullString{
+			String: params.Username,
+			Valid:  params.Username != "",
+		},
 	}
 
-	user, err := cfg.db.CreateUser(r.Context(), userParams)
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
 	if err != nil {
 		respondWithError(w, 500, "Error creating user", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.CreateUser(r.Context(), userParams)
+	if isUniqueViolation(err) {
+		respondWithError(w, 409, "Email or username already taken", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error creating user", err)
+		return
+	}
+
+	verificationToken, err := cfg.createEmailVerification(r.Context(), qtx, user)
+	if err != nil {
+		respondWithError(w, 500, "Error creating user", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error creating user", err)
+		return
+	}
+
+	// The account exists either way; a lost email can be sent again
+	// through the resend endpoint.
+	err = cfg.sendVerificationEmail(r.Context(), user.Email, verificationToken)
+	if err != nil {
+		log.Printf("Error sending verification email: %s", err)
+	}
+
 	resp.ID = user.ID
 	resp.Email = user.Email
 	resp.CreatedAt = user.CreatedAt
 	resp.UpdatedAt = user.UpdatedAt
 	resp.IsChirpyRed = user.IsChirpyRed.Bool
+	resp.Username = user.Username.String
+	resp.DisplayName = user.DisplayName
+	resp.Bio = user.Bio
+	resp.EmailVerified = user.EmailVerifiedAt.Valid
+	resp.TwoFactorEnabled = user.TotpEnabledAt.Valid
 
 	respondWithJson(w, 201, resp)
 }
 
+func (cfg *apiConfig) handlerGetUserProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := cfg.db.GetUserByUsername(r.Context(), r.PathValue("username"))
+	if err != nil {
+		respondWithError(w, 404, "User not found", err)
+		return
+	}
+
+	pins, err := cfg.visiblePins(r.Context(), []database.User{user}, cfg.viewerID(r))
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve user", err)
+		return
+	}
+
+	respondWithJson(w, 200, newPublicUserResponse(user, pins))
+}
+
 // Webhooks
 
 func (cfg *apiConfig) HandlerUpdateIsChirpyRed(w http.ResponseWriter, r *http.Request) {
@@ -446,6 +1425,10 @@ func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, 401, "Unauthorized", err)
 		return
 	}
+	if user.SuspendedAt.Valid {
+		respondWithError(w, 403, "Account suspended", nil)
+		return
+	}
 
 	accessToken, err := auth.MakeJWT(user.ID, cfg.secret, time.Hour)
 	if err != nil {
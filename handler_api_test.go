@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+func TestPublicUserResponsePinnedChirp(t *testing.T) {
+	followersOnly := uuid.New()
+	user := database.User{
+		ID:            uuid.New(),
+		PinnedChirpID: uuid.NullUUID{UUID: followersOnly, Valid: true},
+	}
+
+	// A non-follower cannot read the followers-only chirp, so visiblePins
+	// does not return it and the pin must not show up at all.
+	resp := newPublicUserResponse(user, nil)
+	if resp.PinnedChirpID != nil {
+		t.Fatalf("non-follower: expected no pinned chirp, got %v", *resp.PinnedChirpID)
+	}
+	dat, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("json.Marshal: expected no error, got %v", err)
+	}
+	if strings.Contains(string(dat), "pinned_chirp_id") || strings.Contains(string(dat), followersOnly.String()) {
+		t.Fatalf("non-follower: expected pinned_chirp_id to be left out, got %s", dat)
+	}
+
+	resp = newPublicUserResponse(user, map[uuid.UUID]bool{uuid.New(): true})
+	if resp.PinnedChirpID != nil {
+		t.Fatalf("other visible chirps: expected no pinned chirp, got %v", *resp.PinnedChirpID)
+	}
+
+	resp = newPublicUserResponse(user, map[uuid.UUID]bool{followersOnly: true})
+	if resp.PinnedChirpID == nil || *resp.PinnedChirpID != followersOnly {
+		t.Fatalf("follower: expected pinned chirp %v, got %v", followersOnly, resp.PinnedChirpID)
+	}
+}
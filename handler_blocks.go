@@ -0,0 +1,263 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+type BlockResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+type BlockPage struct {
+	Users      []BlockResponse `json:"users"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+type MuteResponse struct {
+	UserID  uuid.UUID `json:"user_id"`
+	MutedAt time.Time `json:"muted_at"`
+}
+
+type MutePage struct {
+	Users      []MuteResponse `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// handlerBlockUser blocks a user. The blocked user stops seeing the
+// blocker's chirps, and with them every way to interact with them, and any
+// follows between the two are removed.
+func (cfg *apiConfig) handlerBlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	blockedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided UserID could not be parsed", err)
+		return
+	}
+	if blockedID == userID {
+		respondWithError(w, 400, "You cannot block yourself", nil)
+		return
+	}
+
+	_, err = cfg.db.GetUser(r.Context(), blockedID)
+	if err != nil {
+		respondWithError(w, 404, "User not found", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Could not block user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.CreateBlock(r.Context(), database.CreateBlockParams{
+		BlockerID: userID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not block user", err)
+		return
+	}
+
+	err = qtx.DeleteFollowsBetween(r.Context(), database.DeleteFollowsBetweenParams{
+		FollowerID: userID,
+		FolloweeID: blockedID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not block user", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Could not block user", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	blockedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided UserID could not be parsed", err)
+		return
+	}
+
+	err = cfg.db.DeleteBlock(r.Context(), database.DeleteBlockParams{
+		BlockerID: userID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not unblock user", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetMyBlocks(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	blocks, err := cfg.db.GetBlocks(r.Context(), database.GetBlocksParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve blocked users", err)
+		return
+	}
+
+	resp := BlockPage{Users: []BlockResponse{}}
+	if len(blocks) > int(page.Limit) {
+		blocks = blocks[:page.Limit]
+		last := blocks[len(blocks)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.BlockedID})
+	}
+	for _, block := range blocks {
+		resp.Users = append(resp.Users, BlockResponse{
+			UserID:    block.BlockedID,
+			BlockedAt: block.CreatedAt,
+		})
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
+
+// handlerMuteUser mutes a user. Muting only affects the muter's own chirp
+// listings; the muted user is not told and can still interact.
+func (cfg *apiConfig) handlerMuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	mutedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided UserID could not be parsed", err)
+		return
+	}
+	if mutedID == userID {
+		respondWithError(w, 400, "You cannot mute yourself", nil)
+		return
+	}
+
+	_, err = cfg.db.GetUser(r.Context(), mutedID)
+	if err != nil {
+		respondWithError(w, 404, "User not found", err)
+		return
+	}
+
+	err = cfg.db.CreateMute(r.Context(), database.CreateMuteParams{
+		MuterID:   userID,
+		MutedID:   mutedID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not mute user", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnmuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	mutedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided UserID could not be parsed", err)
+		return
+	}
+
+	err = cfg.db.DeleteMute(r.Context(), database.DeleteMuteParams{
+		MuterID: userID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not unmute user", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetMyMutes(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	mutes, err := cfg.db.GetMutes(r.Context(), database.GetMutesParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve muted users", err)
+		return
+	}
+
+	resp := MutePage{Users: []MuteResponse{}}
+	if len(mutes) > int(page.Limit) {
+		mutes = mutes[:page.Limit]
+		last := mutes[len(mutes)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.MutedID})
+	}
+	for _, mute := range mutes {
+		resp.Users = append(resp.Users, MuteResponse{
+			UserID:  mute.MutedID,
+			MutedAt: mute.CreatedAt,
+		})
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
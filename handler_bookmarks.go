@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerBookmarkChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+		ID:       parsedChirpID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+
+	err = cfg.db.CreateBookmark(r.Context(), database.CreateBookmarkParams{
+		UserID:    userID,
+		ChirpID:   chirp.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not bookmark chirp", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerRemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	err = cfg.db.DeleteBookmark(r.Context(), database.DeleteBookmarkParams{
+		UserID:  userID,
+		ChirpID: parsedChirpID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not remove bookmark", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+// handlerGetMyBookmarks lists the caller's bookmarks, most recently saved
+// first. There is no way to read another user's bookmarks.
+func (cfg *apiConfig) handlerGetMyBookmarks(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	rows, err := cfg.db.GetBookmarks(r.Context(), database.GetBookmarksParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve bookmarks", err)
+		return
+	}
+
+	resp := ChirpPage{}
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.BookmarkedAt, ID: last.Chirp.ID})
+	}
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+	resp.Chirps, err = cfg.buildChirpResponses(r.Context(), chirps, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve bookmarks", err)
+		return
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
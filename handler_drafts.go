@@ -0,0 +1,349 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+type DraftResponse struct {
+	ID         uuid.UUID     `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Body       string        `json:"body"`
+	UserID     uuid.UUID     `json:"user_id"`
+	InReplyTo  uuid.NullUUID `json:"in_reply_to"`
+	QuoteOf    uuid.NullUUID `json:"quote_of"`
+	Visibility string        `json:"visibility"`
+}
+
+func newDraftResponse(draft database.Draft) DraftResponse {
+	return DraftResponse{
+		ID:         draft.ID,
+		CreatedAt:  draft.CreatedAt,
+		UpdatedAt:  draft.UpdatedAt,
+		Body:       draft.Body,
+		UserID:     draft.UserID,
+		InReplyTo:  draft.InReplyTo,
+		QuoteOf:    draft.QuoteOf,
+		Visibility: draft.Visibility,
+	}
+}
+
+type DraftPage struct {
+	Drafts     []DraftResponse `json:"drafts"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// decodeDraft reads a draft from the request body and validates it the same
+// way handlerCreateChirp validates a chirp. On failure it responds itself and
+// returns false.
+func (cfg *apiConfig) decodeDraft(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (newChirp, bool) {
+	type parameters struct {
+		Body       string        `json:"body"`
+		InReplyTo  uuid.NullUUID `json:"in_reply_to"`
+		QuoteOf    uuid.NullUUID `json:"quote_of"`
+		Visibility string        `json:"visibility"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return newChirp{}, false
+	}
+
+	// The status is decided again when the draft is published, against the
+	// rules in force then.
+	cleanBody, _, err := cfg.cleanChirpBody(params.Body)
+	if errors.Is(err, errChirpRejected) {
+		respondWithError(w, 422, err.Error(), err)
+		return newChirp{}, false
+	}
+	if err != nil {
+		respondWithError(w, 400, err.Error(), nil)
+		return newChirp{}, false
+	}
+
+	visibility, err := validateVisibility(params.Visibility)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), nil)
+		return newChirp{}, false
+	}
+
+	refs, err := resolveChirpReferences(r.Context(), cfg.db, userID, params.InReplyTo, params.QuoteOf)
+	if errors.Is(err, errParentNotFound) || errors.Is(err, errQuotedNotFound) {
+		respondWithError(w, 404, err.Error(), err)
+		return newChirp{}, false
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error saving draft", err)
+		return newChirp{}, false
+	}
+
+	return newChirp{
+		UserID:     userID,
+		Body:       cleanBody,
+		InReplyTo:  refs.InReplyTo,
+		QuoteOf:    refs.QuoteOf,
+		Visibility: visibility,
+	}, true
+}
+
+func (cfg *apiConfig) handlerCreateDraft(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	params, ok := cfg.decodeDraft(w, r, userID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	draft, err := cfg.db.CreateDraft(r.Context(), database.CreateDraftParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		UserID:     userID,
+		Body:       params.Body,
+		InReplyTo:  params.InReplyTo,
+		QuoteOf:    params.QuoteOf,
+		Visibility: params.Visibility,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error saving draft", err)
+		return
+	}
+
+	respondWithJson(w, 201, newDraftResponse(draft))
+}
+
+func (cfg *apiConfig) handlerGetDrafts(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorUpdatedAt, cursorID := cursorParams(page.Cursor)
+
+	drafts, err := cfg.db.GetDrafts(r.Context(), database.GetDraftsParams{
+		UserID:          userID,
+		CursorUpdatedAt: cursorUpdatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve drafts", err)
+		return
+	}
+
+	resp := DraftPage{Drafts: []DraftResponse{}}
+	if len(drafts) > int(page.Limit) {
+		drafts = drafts[:page.Limit]
+		last := drafts[len(drafts)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.UpdatedAt, ID: last.ID})
+	}
+	for _, draft := range drafts {
+		resp.Drafts = append(resp.Drafts, newDraftResponse(draft))
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
+
+// handlerGetDraft only finds the caller's own drafts. Drafts are private, so
+// another user's draft is reported as missing rather than forbidden.
+func (cfg *apiConfig) handlerGetDraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided DraftID could not be parsed", err)
+		return
+	}
+
+	draft, err := cfg.db.GetDraft(r.Context(), database.GetDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Could not retrieve draft", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve draft", err)
+		return
+	}
+
+	respondWithJson(w, 200, newDraftResponse(draft))
+}
+
+func (cfg *apiConfig) handlerUpdateDraft(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided DraftID could not be parsed", err)
+		return
+	}
+
+	params, ok := cfg.decodeDraft(w, r, userID)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.db.UpdateDraft(r.Context(), database.UpdateDraftParams{
+		Body:       params.Body,
+		InReplyTo:  params.InReplyTo,
+		QuoteOf:    params.QuoteOf,
+		Visibility: params.Visibility,
+		UpdatedAt:  time.Now().UTC(),
+		ID:         draftID,
+		UserID:     userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Could not retrieve draft", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error saving draft", err)
+		return
+	}
+
+	respondWithJson(w, 200, newDraftResponse(draft))
+}
+
+func (cfg *apiConfig) handlerDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided DraftID could not be parsed", err)
+		return
+	}
+
+	deleted, err := cfg.db.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not delete draft", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, 404, "Could not retrieve draft", nil)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+// handlerPublishDraft turns a draft into a chirp. The draft row stays locked
+// until the chirp is committed, so concurrent publishes create one chirp.
+func (cfg *apiConfig) handlerPublishDraft(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided DraftID could not be parsed", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Error publishing draft", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	draft, err := qtx.GetDraftForUpdate(r.Context(), database.GetDraftForUpdateParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Could not retrieve draft", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error publishing draft", err)
+		return
+	}
+
+	body, status, err := cfg.cleanChirpBody(draft.Body)
+	if errors.Is(err, errChirpRejected) {
+		respondWithError(w, 422, err.Error(), err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 400, err.Error(), nil)
+		return
+	}
+
+	chirp, err := createChirp(r.Context(), qtx, newChirp{
+		UserID:     userID,
+		Body:       body,
+		InReplyTo:  draft.InReplyTo,
+		QuoteOf:    draft.QuoteOf,
+		Visibility: draft.Visibility,
+		Status:     status,
+	})
+	if errors.Is(err, errParentNotFound) || errors.Is(err, errQuotedNotFound) {
+		respondWithError(w, 404, err.Error(), err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error publishing draft", err)
+		return
+	}
+
+	_, err = qtx.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draft.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error publishing draft", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error publishing draft", err)
+		return
+	}
+
+	resp, err := cfg.buildChirpResponse(r.Context(), chirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, "Error publishing draft", err)
+		return
+	}
+
+	respondWithJson(w, 201, resp)
+}
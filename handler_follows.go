@@ -0,0 +1,217 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+type FollowResponse struct {
+	UserID     uuid.UUID `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type FollowPage struct {
+	Users      []FollowResponse `json:"users"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerFollowUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided UserID could not be parsed", err)
+		return
+	}
+	if followeeID == userID {
+		respondWithError(w, 400, "You cannot follow yourself", nil)
+		return
+	}
+
+	_, err = cfg.db.GetUser(r.Context(), followeeID)
+	if err != nil {
+		respondWithError(w, 404, "User not found", err)
+		return
+	}
+
+	blocked, err := cfg.db.HasBlocked(r.Context(), database.HasBlockedParams{
+		BlockerID: followeeID,
+		BlockedID: userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not follow user", err)
+		return
+	}
+	if blocked {
+		respondWithError(w, 403, "You have been blocked by this user", nil)
+		return
+	}
+
+	err = cfg.db.CreateFollow(r.Context(), database.CreateFollowParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+		CreatedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not follow user", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided UserID could not be parsed", err)
+		return
+	}
+
+	err = cfg.db.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not unfollow user", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerGetFollowers(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided UserID could not be parsed", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	follows, err := cfg.db.GetFollowers(r.Context(), database.GetFollowersParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve followers", err)
+		return
+	}
+
+	resp := FollowPage{Users: []FollowResponse{}}
+	if len(follows) > int(page.Limit) {
+		follows = follows[:page.Limit]
+		last := follows[len(follows)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.FollowerID})
+	}
+	for _, follow := range follows {
+		resp.Users = append(resp.Users, FollowResponse{
+			UserID:     follow.FollowerID,
+			FollowedAt: follow.CreatedAt,
+		})
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
+
+func (cfg *apiConfig) handlerGetFollowing(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided UserID could not be parsed", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	follows, err := cfg.db.GetFollowing(r.Context(), database.GetFollowingParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve followed users", err)
+		return
+	}
+
+	resp := FollowPage{Users: []FollowResponse{}}
+	if len(follows) > int(page.Limit) {
+		follows = follows[:page.Limit]
+		last := follows[len(follows)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.FolloweeID})
+	}
+	for _, follow := range follows {
+		resp.Users = append(resp.Users, FollowResponse{
+			UserID:     follow.FolloweeID,
+			FollowedAt: follow.CreatedAt,
+		})
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
+
+func (cfg *apiConfig) handlerGetTimeline(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	chirps, err := cfg.db.GetTimeline(r.Context(), database.GetTimelineParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve timeline", err)
+		return
+	}
+
+	resp := ChirpPage{}
+	if len(chirps) > int(page.Limit) {
+		chirps = chirps[:page.Limit]
+		last := chirps[len(chirps)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	resp.Chirps, err = cfg.buildChirpResponses(r.Context(), chirps, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve timeline", err)
+		return
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
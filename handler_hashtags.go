@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+	defaultTrendingLimit  = 10
+)
+
+type TrendingHashtagResponse struct {
+	Tag   string  `json:"tag"`
+	Uses  int64   `json:"uses"`
+	Score float64 `json:"score"`
+}
+
+func (cfg *apiConfig) handlerGetHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	tags := extractHashtags("#" + strings.TrimPrefix(r.PathValue("tag"), "#"))
+	if len(tags) != 1 {
+		respondWithError(w, 400, "Invalid hashtag", nil)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	viewerID := cfg.viewerID(r)
+	chirps, err := cfg.db.GetHashtagChirps(r.Context(), database.GetHashtagChirpsParams{
+		Name:            tags[0],
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		ViewerID:        viewerID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve chirps", err)
+		return
+	}
+
+	resp := ChirpPage{}
+	if len(chirps) > int(page.Limit) {
+		chirps = chirps[:page.Limit]
+		last := chirps[len(chirps)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	resp.Chirps, err = cfg.buildChirpResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve chirps", err)
+		return
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
+
+func (cfg *apiConfig) handlerGetTrendingHashtags(w http.ResponseWriter, r *http.Request) {
+	window := defaultTrendingWindow
+	if param := r.URL.Query().Get("window"); param != "" {
+		parsed, err := time.ParseDuration(param)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, 400, "window must be a positive duration such as 6h", err)
+			return
+		}
+		window = min(parsed, maxTrendingWindow)
+	}
+
+	limit := defaultTrendingLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1 {
+			respondWithError(w, 400, "limit must be a positive integer", err)
+			return
+		}
+		limit = min(parsed, maxPageLimit)
+	}
+
+	now := time.Now().UTC()
+	rows, err := cfg.db.GetTrendingHashtags(r.Context(), database.GetTrendingHashtagsParams{
+		Now:   now,
+		Since: now.Add(-window),
+		Limit: int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve trending hashtags", err)
+		return
+	}
+
+	resp := []TrendingHashtagResponse{}
+	for _, row := range rows {
+		resp = append(resp, TrendingHashtagResponse{
+			Tag:   row.Name,
+			Uses:  row.Uses,
+			Score: row.Score,
+		})
+	}
+
+	respondWithJson(w, 200, resp)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerLikeChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+		ID:       parsedChirpID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+
+	err = cfg.db.CreateChirpLike(r.Context(), database.CreateChirpLikeParams{
+		ChirpID:   chirp.ID,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not like chirp", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	err = cfg.db.DeleteChirpLike(r.Context(), database.DeleteChirpLikeParams{
+		ChirpID: parsedChirpID,
+		UserID:  userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not unlike chirp", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
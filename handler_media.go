@@ -0,0 +1,62 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/jradziejewski/chirpy/internal/storage"
+)
+
+type MediaResponse struct {
+	URL             string `json:"url"`
+	ThumbnailURL    string `json:"thumbnail_url"`
+	ContentType     string `json:"content_type"`
+	Width           int32  `json:"width"`
+	Height          int32  `json:"height"`
+	ThumbnailWidth  int32  `json:"thumbnail_width"`
+	ThumbnailHeight int32  `json:"thumbnail_height"`
+	AltText         string `json:"alt_text"`
+}
+
+// handlerGetMedia serves stored images to viewers who may see a chirp that
+// uses them. Images of public chirps may be cached by anyone; the others
+// only by the viewer's own client. Neither is immutable, since the chirp
+// can be deleted or hidden later.
+func (cfg *apiConfig) handlerGetMedia(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	visibility, err := cfg.db.GetVisibleMediaObject(r.Context(), database.GetVisibleMediaObjectParams{
+		Name:     name,
+		ViewerID: cfg.viewerID(r),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Media not found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve media", err)
+		return
+	}
+
+	f, err := cfg.media.Open(r.Context(), name)
+	if errors.Is(err, storage.ErrNotFound) {
+		respondWithError(w, 404, "Media not found", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve media", err)
+		return
+	}
+	defer f.Close()
+
+	if visibility == visibilityPublic {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	} else {
+		w.Header().Set("Cache-Control", "private, max-age=3600")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, name, time.Time{}, f)
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/jradziejewski/chirpy/internal/moderation"
+)
+
+type ModerationRuleResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Term      string    `json:"term"`
+	Action    string    `json:"action"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newModerationRuleResponse(rule database.ModerationRule) ModerationRuleResponse {
+	return ModerationRuleResponse{
+		ID:        rule.ID,
+		Term:      rule.Term,
+		Action:    rule.Action,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
+
+func (cfg *apiConfig) handlerGetModerationRules(w http.ResponseWriter, r *http.Request) {
+	_, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := cfg.db.GetModerationRules(r.Context())
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve moderation rules", err)
+		return
+	}
+
+	resp := []ModerationRuleResponse{}
+	for _, rule := range rules {
+		resp = append(resp, newModerationRuleResponse(rule))
+	}
+
+	respondWithJson(w, 200, resp)
+}
+
+// handlerSaveModerationRule adds a rule, or changes the action of the rule
+// for the same term. It takes effect on this instance immediately and on
+// the others at their next reload.
+func (cfg *apiConfig) handlerSaveModerationRule(w http.ResponseWriter, r *http.Request) {
+	_, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Term   string `json:"term"`
+		Action string `json:"action"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	rule := moderation.Rule{
+		Term:   strings.ToLower(strings.TrimSpace(params.Term)),
+		Action: moderation.Action(params.Action),
+	}
+	_, err = moderation.NewFilter([]moderation.Rule{rule})
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+
+	now := time.Now().UTC()
+	saved, err := cfg.db.UpsertModerationRule(r.Context(), database.UpsertModerationRuleParams{
+		ID:        uuid.New(),
+		Term:      rule.Term,
+		Action:    string(rule.Action),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not save moderation rule", err)
+		return
+	}
+
+	err = cfg.loadModerationRules(r.Context())
+	if err != nil {
+		respondWithError(w, 500, "Could not reload moderation rules", err)
+		return
+	}
+
+	respondWithJson(w, 200, newModerationRuleResponse(saved))
+}
+
+func (cfg *apiConfig) handlerDeleteModerationRule(w http.ResponseWriter, r *http.Request) {
+	_, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided rule ID could not be parsed", err)
+		return
+	}
+
+	deleted, err := cfg.db.DeleteModerationRule(r.Context(), ruleID)
+	if err != nil {
+		respondWithError(w, 500, "Could not delete moderation rule", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, 404, "Could not retrieve moderation rule", nil)
+		return
+	}
+
+	err = cfg.loadModerationRules(r.Context())
+	if err != nil {
+		respondWithError(w, 500, "Could not reload moderation rules", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+type ModerationQueueItem struct {
+	Chirp   ChirpResponse    `json:"chirp"`
+	Reports []ReportResponse `json:"reports"`
+}
+
+type ModerationQueuePage struct {
+	Items      []ModerationQueueItem `json:"items"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// handlerGetModerationQueue lists chirps awaiting review, oldest first: those
+// held by the moderation pipeline and those with open reports.
+func (cfg *apiConfig) handlerGetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	_, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	chirps, err := cfg.db.GetModerationQueue(r.Context(), database.GetModerationQueueParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve moderation queue", err)
+		return
+	}
+
+	resp := ModerationQueuePage{Items: []ModerationQueueItem{}}
+	if len(chirps) > int(page.Limit) {
+		chirps = chirps[:page.Limit]
+		last := chirps[len(chirps)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+	reports, err := cfg.db.GetOpenReportsForChirps(r.Context(), chirpIDs)
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve moderation queue", err)
+		return
+	}
+	reportsByChirp := make(map[uuid.UUID][]ReportResponse)
+	for _, report := range reports {
+		reportsByChirp[report.ChirpID] = append(reportsByChirp[report.ChirpID], newReportResponse(report))
+	}
+
+	for _, chirp := range chirps {
+		item := ModerationQueueItem{
+			Chirp:   newChirpResponse(chirp),
+			Reports: reportsByChirp[chirp.ID],
+		}
+		if item.Reports == nil {
+			item.Reports = []ReportResponse{}
+		}
+		resp.Items = append(resp.Items, item)
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
+
+type ModerationActionResponse struct {
+	ID           uuid.UUID     `json:"id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	ModeratorID  uuid.NullUUID `json:"moderator_id"`
+	Action       string        `json:"action"`
+	ChirpID      uuid.NullUUID `json:"chirp_id"`
+	TargetUserID uuid.NullUUID `json:"target_user_id"`
+	Note         string        `json:"note"`
+}
+
+func newModerationActionResponse(action database.ModerationAction) ModerationActionResponse {
+	return ModerationActionResponse{
+		ID:           action.ID,
+		CreatedAt:    action.CreatedAt,
+		ModeratorID:  action.ModeratorID,
+		Action:       action.Action,
+		ChirpID:      action.ChirpID,
+		TargetUserID: action.TargetUserID,
+		Note:         action.Note,
+	}
+}
+
+// handlerModerateChirp applies a moderator's decision to a chirp:
+//   - resolve closes its open reports and leaves the chirp as it is;
+//   - dismiss closes its open reports and publishes it if it was held;
+//   - hide closes its open reports and hides it from everyone;
+//   - suspend does what hide does and also suspends the author, signing
+//     them out everywhere.
+//
+// Every decision is recorded in the moderation log.
+func (cfg *apiConfig) handlerModerateChirp(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	type parameters struct {
+		Action string `json:"action"`
+		Note   string `json:"note"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	switch params.Action {
+	case moderationActionResolve, moderationActionDismiss, moderationActionHide, moderationActionSuspend:
+	default:
+		respondWithError(w, 400, fmt.Sprintf("Unknown moderation action %q", params.Action), nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Error moderating chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := qtx.GetChirpForUpdate(r.Context(), parsedChirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error moderating chirp", err)
+		return
+	}
+	if chirp.DeletedAt.Valid {
+		respondWithError(w, 404, "Could not retrieve chirp", nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	reportStatus := reportStatusResolved
+	if params.Action == moderationActionDismiss {
+		reportStatus = reportStatusDismissed
+	}
+	closed, err := qtx.CloseChirpReports(r.Context(), database.CloseChirpReportsParams{
+		Status:    reportStatus,
+		UpdatedAt: now,
+		ChirpID:   chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error moderating chirp", err)
+		return
+	}
+
+	newStatus := chirp.Status
+	switch params.Action {
+	case moderationActionResolve:
+		if closed == 0 {
+			respondWithError(w, 409, "Chirp has no open reports", nil)
+			return
+		}
+	case moderationActionDismiss:
+		if closed == 0 && chirp.Status != chirpStatusHeld {
+			respondWithError(w, 409, "Chirp is not awaiting review", nil)
+			return
+		}
+		if chirp.Status == chirpStatusHeld {
+			newStatus = chirpStatusPublished
+		}
+	case moderationActionHide, moderationActionSuspend:
+		newStatus = chirpStatusHidden
+	}
+
+	if newStatus != chirp.Status {
+		err = qtx.UpdateChirpStatus(r.Context(), database.UpdateChirpStatusParams{
+			Status: newStatus,
+			ID:     chirp.ID,
+		})
+		if err != nil {
+			respondWithError(w, 500, "Error moderating chirp", err)
+			return
+		}
+	}
+
+	if params.Action == moderationActionSuspend {
+		err = qtx.SuspendUser(r.Context(), database.SuspendUserParams{
+			SuspendedAt: sql.NullTime{Time: now, Valid: true},
+			ID:          chirp.UserID,
+		})
+		if err != nil {
+			respondWithError(w, 500, "Error suspending user", err)
+			return
+		}
+		err = qtx.RevokeUserTokens(r.Context(), database.RevokeUserTokensParams{
+			RevokedAt: sql.NullTime{Time: now, Valid: true},
+			UserID:    chirp.UserID,
+		})
+		if err != nil {
+			respondWithError(w, 500, "Error suspending user", err)
+			return
+		}
+	}
+
+	action, err := qtx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		ID:           uuid.New(),
+		CreatedAt:    now,
+		ModeratorID:  uuid.NullUUID{UUID: moderatorID, Valid: true},
+		Action:       params.Action,
+		ChirpID:      uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		TargetUserID: uuid.NullUUID{UUID: chirp.UserID, Valid: true},
+		Note:         params.Note,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error moderating chirp", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error moderating chirp", err)
+		return
+	}
+
+	respondWithJson(w, 200, newModerationActionResponse(action))
+}
+
+type ModerationActionPage struct {
+	Actions    []ModerationActionResponse `json:"actions"`
+	NextCursor string                     `json:"next_cursor,omitempty"`
+}
+
+// handlerGetModerationActions lists the moderation log, newest first.
+func (cfg *apiConfig) handlerGetModerationActions(w http.ResponseWriter, r *http.Request) {
+	_, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorCreatedAt, cursorID := cursorParams(page.Cursor)
+
+	actions, err := cfg.db.GetModerationActions(r.Context(), database.GetModerationActionsParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve moderation actions", err)
+		return
+	}
+
+	resp := ModerationActionPage{Actions: []ModerationActionResponse{}}
+	if len(actions) > int(page.Limit) {
+		actions = actions[:page.Limit]
+		last := actions[len(actions)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	for _, action := range actions {
+		resp.Actions = append(resp.Actions, newModerationActionResponse(action))
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jradziejewski/chirpy/internal/auth"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+// handlerForgotPassword starts a password reset. It answers 202 whether or
+// not the email belongs to an account, and leaves the work to the password
+// reset workers so response times do not tell either. Limits apply to
+// every email alike, so a 429 does not tell either.
+func (cfg *apiConfig) handlerForgotPassword(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Email string `json:"email"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	if !cfg.passwordResetIPLimiter.Allow(clientIP(r)) ||
+		!cfg.passwordResetEmailLimiter.Allow(strings.ToLower(strings.TrimSpace(params.Email))) {
+		respondWithError(w, 429, "Too many password reset requests, try again later", nil)
+		return
+	}
+
+	if !cfg.queuePasswordReset(params.Email) {
+		log.Printf("Password reset queue full, refusing request")
+		respondWithError(w, 503, "Password resets are busy, try again later", nil)
+		return
+	}
+
+	w.WriteHeader(202)
+}
+
+// handlerResetPassword sets a new password using an emailed reset token.
+// The token works once, and every refresh token of the account is revoked
+// so sessions started with the old password end.
+func (cfg *apiConfig) handlerResetPassword(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+	if params.Password == "" {
+		respondWithError(w, 400, "Password must not be empty", nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Error resetting password", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	reset, err := qtx.GetPasswordResetForUpdate(r.Context(), auth.HashToken(params.Token))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 400, "Invalid or expired token", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error resetting password", err)
+		return
+	}
+	now := time.Now().UTC()
+	if reset.UsedAt.Valid || now.After(reset.ExpiresAt) {
+		respondWithError(w, 400, "Invalid or expired token", nil)
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(params.Password)
+	if err != nil {
+		respondWithError(w, 500, "Error hashing password", err)
+		return
+	}
+
+	err = qtx.UpdatePassword(r.Context(), database.UpdatePasswordParams{
+		HashedPassword: sql.NullString{String: hashedPassword, Valid: true},
+		ID:             reset.UserID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error resetting password", err)
+		return
+	}
+
+	err = qtx.UsePasswordReset(r.Context(), database.UsePasswordResetParams{
+		UsedAt:    sql.NullTime{Time: now, Valid: true},
+		TokenHash: reset.TokenHash,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error resetting password", err)
+		return
+	}
+
+	err = qtx.RevokeUserTokens(r.Context(), database.RevokeUserTokensParams{
+		RevokedAt: sql.NullTime{Time: now, Valid: true},
+		UserID:    reset.UserID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error resetting password", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error resetting password", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+// handlerPinChirp pins one of the caller's chirps to their profile, replacing
+// any previous pin. A null chirp_id removes the pin.
+func (cfg *apiConfig) handlerPinChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		ChirpID uuid.NullUUID `json:"chirp_id"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	if params.ChirpID.Valid {
+		chirp, err := cfg.db.GetChirp(r.Context(), params.ChirpID.UUID)
+		if err != nil {
+			respondWithError(w, 404, "Could not retrieve chirp", err)
+			return
+		}
+		if chirp.DeletedAt.Valid {
+			respondWithError(w, 404, "Could not retrieve chirp", nil)
+			return
+		}
+		if chirp.UserID != userID {
+			respondWithError(w, 403, "Forbidden", nil)
+			return
+		}
+	}
+
+	user, err := cfg.db.UpdatePinnedChirp(r.Context(), database.UpdatePinnedChirpParams{
+		PinnedChirpID: params.ChirpID,
+		ID:            userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not pin chirp", err)
+		return
+	}
+
+	pins, err := cfg.visiblePins(r.Context(), []database.User{user}, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, "Could not pin chirp", err)
+		return
+	}
+
+	respondWithJson(w, 200, newPublicUserResponse(user, pins))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerVotePoll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	type parameters struct {
+		OptionID uuid.UUID `json:"option_id"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+		ID:       parsedChirpID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+
+	poll, err := cfg.db.GetPollByChirpID(r.Context(), chirp.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Chirp does not have a poll", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve poll", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	if !now.Before(poll.ClosesAt) {
+		respondWithError(w, 409, "Poll has closed", nil)
+		return
+	}
+
+	// The primary key on poll_votes allows one vote per user, and the
+	// foreign key rejects options that belong to a different poll.
+	err = cfg.db.CreatePollVote(r.Context(), database.CreatePollVoteParams{
+		PollID:    poll.ID,
+		UserID:    userID,
+		OptionID:  params.OptionID,
+		CreatedAt: now,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, 409, "Already voted in this poll", err)
+		return
+	}
+	if isForeignKeyViolation(err) {
+		respondWithError(w, 400, "Option does not belong to this poll", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not record vote", err)
+		return
+	}
+
+	polls, err := cfg.loadPolls(r.Context(), []uuid.UUID{parsedChirpID}, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve poll", err)
+		return
+	}
+
+	respondWithJson(w, 201, polls[parsedChirpID])
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerRechirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	viewerID := uuid.NullUUID{UUID: userID, Valid: true}
+	original, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+		ID:       parsedChirpID,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+	// Rechirping a rechirp re-shares the chirp it points to.
+	if original.RechirpOf.Valid {
+		original, err = cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+			ID:       original.RechirpOf.UUID,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			respondWithError(w, 404, "Could not retrieve chirp", err)
+			return
+		}
+	}
+	// A rechirp is public, so it must not re-share a restricted chirp to
+	// people the author did not pick.
+	if original.Visibility != visibilityPublic {
+		respondWithError(w, 403, "Only public chirps can be rechirped", nil)
+		return
+	}
+	rechirpOf := uuid.NullUUID{UUID: original.ID, Valid: true}
+
+	existing, err := cfg.db.GetRechirp(r.Context(), database.GetRechirpParams{
+		UserID:    userID,
+		RechirpOf: rechirpOf,
+	})
+	if err == nil {
+		resp, err := cfg.buildChirpResponse(r.Context(), existing, viewerID)
+		if err != nil {
+			respondWithError(w, 500, "Error creating rechirp", err)
+			return
+		}
+		respondWithJson(w, 200, resp)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 500, "Error creating rechirp", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	rechirp, err := cfg.db.CreateChirp(r.Context(), database.CreateChirpParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Body:       "",
+		UserID:     userID,
+		RechirpOf:  rechirpOf,
+		Visibility: visibilityPublic,
+		Status:     chirpStatusPublished,
+	})
+	status := 201
+	// A concurrent request got there first; answer as if it had finished
+	// before this one started.
+	if isUniqueViolation(err) {
+		rechirp, err = cfg.db.GetRechirp(r.Context(), database.GetRechirpParams{
+			UserID:    userID,
+			RechirpOf: rechirpOf,
+		})
+		status = 200
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error creating rechirp", err)
+		return
+	}
+
+	resp, err := cfg.buildChirpResponse(r.Context(), rechirp, viewerID)
+	if err != nil {
+		respondWithError(w, 500, "Error creating rechirp", err)
+		return
+	}
+
+	respondWithJson(w, status, resp)
+}
+
+func (cfg *apiConfig) handlerUndoRechirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	err = cfg.db.DeleteRechirp(r.Context(), database.DeleteRechirpParams{
+		UserID:    userID,
+		RechirpOf: uuid.NullUUID{UUID: parsedChirpID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not undo rechirp", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+type ReportResponse struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	ReporterID uuid.UUID `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Details    string    `json:"details"`
+	Status     string    `json:"status"`
+}
+
+func newReportResponse(report database.Report) ReportResponse {
+	return ReportResponse{
+		ID:         report.ID,
+		CreatedAt:  report.CreatedAt,
+		ChirpID:    report.ChirpID,
+		ReporterID: report.ReporterID,
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Status:     report.Status,
+	}
+}
+
+// handlerReportChirp flags a chirp for the moderation queue. Each user can
+// report a chirp once.
+func (cfg *apiConfig) handlerReportChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	type parameters struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	if !reportReasons[params.Reason] {
+		respondWithError(w, 400, fmt.Sprintf("Unknown report reason %q", params.Reason), nil)
+		return
+	}
+	if len(params.Details) > maxReportDetailsLength {
+		respondWithError(w, 400, "Details too long", nil)
+		return
+	}
+
+	chirp, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+		ID:       parsedChirpID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+	if chirp.UserID == userID {
+		respondWithError(w, 400, "You cannot report your own chirp", nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	report, err := cfg.db.CreateReport(r.Context(), database.CreateReportParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		ChirpID:    chirp.ID,
+		ReporterID: userID,
+		Reason:     params.Reason,
+		Details:    params.Details,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, 409, "You have already reported this chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not report chirp", err)
+		return
+	}
+
+	respondWithJson(w, 201, newReportResponse(report))
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+// maxScheduleAhead is how far into the future a chirp can be scheduled.
+const maxScheduleAhead = 365 * 24 * time.Hour
+
+type ScheduledChirpResponse struct {
+	ID         uuid.UUID     `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Body       string        `json:"body"`
+	UserID     uuid.UUID     `json:"user_id"`
+	InReplyTo  uuid.NullUUID `json:"in_reply_to"`
+	QuoteOf    uuid.NullUUID `json:"quote_of"`
+	Visibility string        `json:"visibility"`
+	PublishAt  time.Time     `json:"publish_at"`
+	FailedAt   *time.Time    `json:"failed_at"`
+	Failure    string        `json:"failure,omitempty"`
+}
+
+func newScheduledChirpResponse(scheduled database.ScheduledChirp) ScheduledChirpResponse {
+	return ScheduledChirpResponse{
+		ID:         scheduled.ID,
+		CreatedAt:  scheduled.CreatedAt,
+		UpdatedAt:  scheduled.UpdatedAt,
+		Body:       scheduled.Body,
+		UserID:     scheduled.UserID,
+		InReplyTo:  scheduled.InReplyTo,
+		QuoteOf:    scheduled.QuoteOf,
+		Visibility: scheduled.Visibility,
+		PublishAt:  scheduled.PublishAt,
+		FailedAt:   nullTimePtr(scheduled.FailedAt),
+		Failure:    scheduled.Failure.String,
+	}
+}
+
+type ScheduledChirpPage struct {
+	ScheduledChirps []ScheduledChirpResponse `json:"scheduled_chirps"`
+	NextCursor      string                   `json:"next_cursor,omitempty"`
+}
+
+// scheduleChirp stores a chirp for the publisher to pick up at publishAt.
+// References are checked now so obvious mistakes fail at request time; the
+// publisher resolves them again when the chirp goes out.
+func (cfg *apiConfig) scheduleChirp(w http.ResponseWriter, r *http.Request, chirp newChirp, publishAt time.Time) {
+	now := time.Now().UTC()
+	publishAt = publishAt.UTC()
+	if !publishAt.After(now) {
+		respondWithError(w, 400, "publish_at must be in the future", nil)
+		return
+	}
+	if publishAt.Sub(now) > maxScheduleAhead {
+		respondWithError(w, 400, "publish_at is too far in the future", nil)
+		return
+	}
+
+	refs, err := resolveChirpReferences(r.Context(), cfg.db, chirp.UserID, chirp.InReplyTo, chirp.QuoteOf)
+	if errors.Is(err, errParentNotFound) || errors.Is(err, errQuotedNotFound) {
+		respondWithError(w, 404, err.Error(), err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error scheduling chirp", err)
+		return
+	}
+
+	scheduled, err := cfg.db.CreateScheduledChirp(r.Context(), database.CreateScheduledChirpParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		UserID:     chirp.UserID,
+		Body:       chirp.Body,
+		InReplyTo:  refs.InReplyTo,
+		QuoteOf:    refs.QuoteOf,
+		PublishAt:  publishAt,
+		Visibility: chirp.Visibility,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error scheduling chirp", err)
+		return
+	}
+
+	respondWithJson(w, 201, newScheduledChirpResponse(scheduled))
+}
+
+func (cfg *apiConfig) handlerGetScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+	cursorPublishAt, cursorID := cursorParams(page.Cursor)
+
+	scheduled, err := cfg.db.GetScheduledChirps(r.Context(), database.GetScheduledChirpsParams{
+		UserID:          userID,
+		CursorPublishAt: cursorPublishAt,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve scheduled chirps", err)
+		return
+	}
+
+	resp := ScheduledChirpPage{ScheduledChirps: []ScheduledChirpResponse{}}
+	if len(scheduled) > int(page.Limit) {
+		scheduled = scheduled[:page.Limit]
+		last := scheduled[len(scheduled)-1]
+		resp.NextCursor = encodeCursor(pageCursor{CreatedAt: last.PublishAt, ID: last.ID})
+	}
+	for _, s := range scheduled {
+		resp.ScheduledChirps = append(resp.ScheduledChirps, newScheduledChirpResponse(s))
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
+
+func (cfg *apiConfig) handlerCancelScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := cfg.requireActiveUser(w, r)
+	if !ok {
+		return
+	}
+
+	scheduledID, err := uuid.Parse(r.PathValue("scheduledID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ID could not be parsed", err)
+		return
+	}
+
+	scheduled, err := cfg.db.GetScheduledChirp(r.Context(), scheduledID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Could not retrieve scheduled chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve scheduled chirp", err)
+		return
+	}
+	if scheduled.UserID != userID {
+		respondWithError(w, 403, "Forbidden", nil)
+		return
+	}
+
+	// The publisher may have claimed the chirp in the meantime, in which
+	// case the delete waits for it and then finds nothing left to cancel.
+	deleted, err := cfg.db.DeleteScheduledChirp(r.Context(), scheduledID)
+	if err != nil {
+		respondWithError(w, 500, "Could not cancel scheduled chirp", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, 409, "Chirp has already been published", nil)
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/jradziejewski/chirpy/internal/search"
+)
+
+type SearchResult struct {
+	ChirpResponse
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, r *http.Request) {
+	query, err := search.ParseQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		respondWithError(w, 400, "Query must contain at least one word", err)
+		return
+	}
+
+	userID := uuid.NullUUID{}
+	authorID := r.URL.Query().Get("author_id")
+	if authorID != "" {
+		parsed, err := uuid.Parse(authorID)
+		if err != nil {
+			respondWithError(w, 400, "Could not parse author_id", err)
+			return
+		}
+		userID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error(), err)
+		return
+	}
+
+	cursorRank := sql.NullFloat64{}
+	_, cursorID := cursorParams(page.Cursor)
+	if page.Cursor != nil {
+		if page.Cursor.Rank == nil {
+			respondWithError(w, 400, "Cursor does not belong to a search", nil)
+			return
+		}
+		cursorRank = sql.NullFloat64{Float64: *page.Cursor.Rank, Valid: true}
+	}
+
+	viewerID := cfg.viewerID(r)
+	rows, err := cfg.db.SearchChirps(r.Context(), database.SearchChirpsParams{
+		Query:      query,
+		UserID:     userID,
+		CursorRank: cursorRank,
+		CursorID:   cursorID,
+		ViewerID:   viewerID,
+		Limit:      page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not search chirps", err)
+		return
+	}
+
+	resp := SearchPage{Results: []SearchResult{}}
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		resp.NextCursor = encodeCursor(pageCursor{
+			CreatedAt: last.Chirp.CreatedAt,
+			ID:        last.Chirp.ID,
+			Rank:      &last.Rank,
+		})
+	}
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+	chirpResponses, err := cfg.buildChirpResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, 500, "Could not search chirps", err)
+		return
+	}
+
+	for i, row := range rows {
+		resp.Results = append(resp.Results, SearchResult{
+			ChirpResponse: chirpResponses[i],
+			Rank:          row.Rank,
+			Snippet:       row.Snippet,
+		})
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondWithJson(w, 200, resp)
+}
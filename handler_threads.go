@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+type ThreadNode struct {
+	ChirpResponse
+	Depth      int32         `json:"depth"`
+	ReplyCount int           `json:"reply_count"`
+	Replies    []*ThreadNode `json:"replies"`
+}
+
+// handlerGetChirpThread returns the thread the chirp belongs to, as far as the
+// caller can see it. A reply the caller cannot see hides its replies too, and
+// a hidden root hides the whole thread. Deleted chirps with replies appear as
+// tombstones.
+func (cfg *apiConfig) handlerGetChirpThread(w http.ResponseWriter, r *http.Request) {
+	parsedChirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, "Provided ChirpID could not be parsed", err)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+	chirp, err := cfg.db.GetVisibleChirp(r.Context(), database.GetVisibleChirpParams{
+		ID:       parsedChirpID,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Could not retrieve chirp", err)
+		return
+	}
+
+	rootID := chirp.ID
+	if chirp.RootID.Valid {
+		rootID = chirp.RootID.UUID
+	}
+
+	rows, err := cfg.db.GetChirpThread(r.Context(), database.GetChirpThreadParams{
+		RootID:   rootID,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve thread", err)
+		return
+	}
+	if len(rows) == 0 {
+		respondWithError(w, 404, "Could not retrieve thread", nil)
+		return
+	}
+
+	// A deleted chirp is shown as a tombstone while it still holds replies
+	// together, and left out otherwise. Rows come ordered by depth, so
+	// walking them backwards sees every reply before its parent.
+	keep := make(map[uuid.UUID]bool, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		if !row.Chirp.DeletedAt.Valid || row.Depth == 0 {
+			keep[row.Chirp.ID] = true
+		}
+		if keep[row.Chirp.ID] && row.Chirp.InReplyTo.Valid {
+			keep[row.Chirp.InReplyTo.UUID] = true
+		}
+	}
+
+	live := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		if !row.Chirp.DeletedAt.Valid {
+			live = append(live, row.Chirp)
+		}
+	}
+	liveResponses, err := cfg.buildChirpResponses(r.Context(), live, viewerID)
+	if err != nil {
+		respondWithError(w, 500, "Could not retrieve thread", err)
+		return
+	}
+	chirpResponses := make(map[uuid.UUID]ChirpResponse, len(live))
+	for i, chirp := range live {
+		chirpResponses[chirp.ID] = liveResponses[i]
+	}
+
+	nodes := make(map[uuid.UUID]*ThreadNode, len(rows))
+	var root *ThreadNode
+	for _, row := range rows {
+		if !keep[row.Chirp.ID] {
+			continue
+		}
+		resp, ok := chirpResponses[row.Chirp.ID]
+		if !ok {
+			resp = newTombstoneResponse(row.Chirp)
+		}
+		node := &ThreadNode{
+			ChirpResponse: resp,
+			Depth:         row.Depth,
+			Replies:       []*ThreadNode{},
+		}
+		nodes[row.Chirp.ID] = node
+
+		if row.Depth == 0 {
+			root = node
+			continue
+		}
+		parent, ok := nodes[row.Chirp.InReplyTo.UUID]
+		if !ok {
+			continue
+		}
+		parent.Replies = append(parent.Replies, node)
+		parent.ReplyCount++
+	}
+
+	respondWithJson(w, 200, root)
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jradziejewski/chirpy/internal/auth"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+// handlerEnrollTwoFactor creates a TOTP secret for the caller and returns
+// it with the otpauth:// URI to show as a QR code. 2FA is not on until a
+// first code is confirmed, so enrolling again just replaces the secret. The
+// password is asked for, as in every 2FA change, so a stolen access token
+// cannot tie the account to someone else's authenticator.
+func (cfg *apiConfig) handlerEnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Password string `json:"password"`
+	}
+	type response struct {
+		Secret     string `json:"secret"`
+		OtpauthURI string `json:"otpauth_uri"`
+	}
+	params := parameters{}
+
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		respondWithError(w, 409, "Two-factor authentication is already enabled", nil)
+		return
+	}
+
+	err = auth.CheckPasswordHash(params.Password, user.HashedPassword.String)
+	if err != nil {
+		respondWithError(w, 403, "Wrong password", err)
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		respondWithError(w, 500, "Error generating secret", err)
+		return
+	}
+
+	err = cfg.db.SetTOTPSecret(r.Context(), database.SetTOTPSecretParams{
+		TotpSecret: sql.NullString{String: secret, Valid: true},
+		ID:         user.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error enrolling two-factor authentication", err)
+		return
+	}
+
+	respondWithJson(w, 200, response{
+		Secret:     secret,
+		OtpauthURI: auth.TOTPURI(secret, totpIssuer, user.Email),
+	})
+}
+
+// handlerConfirmTwoFactor turns 2FA on once the caller proves their
+// authenticator produces valid codes, and returns the recovery codes. Like
+// enrolling, it needs the password.
+func (cfg *apiConfig) handlerConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	type response struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	params := parameters{}
+
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		respondWithError(w, 409, "Two-factor authentication is already enabled", nil)
+		return
+	}
+	if !user.TotpSecret.Valid {
+		respondWithError(w, 400, "Two-factor enrollment has not been started", nil)
+		return
+	}
+
+	err = auth.CheckPasswordHash(params.Password, user.HashedPassword.String)
+	if err != nil {
+		respondWithError(w, 403, "Wrong password", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	step, ok := auth.ValidateTOTP(user.TotpSecret.String, params.Code, now)
+	if !ok {
+		respondWithError(w, 400, "Invalid code", nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Error enabling two-factor authentication", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.EnableTOTP(r.Context(), database.EnableTOTPParams{
+		TotpEnabledAt: sql.NullTime{Time: now, Valid: true},
+		TotpLastStep:  sql.NullInt64{Int64: step, Valid: true},
+		ID:            user.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error enabling two-factor authentication", err)
+		return
+	}
+
+	codes, err := cfg.createRecoveryCodes(r.Context(), qtx, user.ID)
+	if err != nil {
+		respondWithError(w, 500, "Error creating recovery codes", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error enabling two-factor authentication", err)
+		return
+	}
+
+	respondWithJson(w, 200, response{RecoveryCodes: codes})
+}
+
+// handlerDisableTwoFactor turns 2FA off. Like account deletion it asks for
+// the password, and also for a code, so a stolen access token cannot be
+// used to weaken the account.
+func (cfg *apiConfig) handlerDisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Password     string `json:"password"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	params := parameters{}
+
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Error disabling two-factor authentication", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondWithError(w, 409, "Two-factor authentication is not enabled", nil)
+		return
+	}
+
+	err = auth.CheckPasswordHash(params.Password, user.HashedPassword.String)
+	if err != nil {
+		respondWithError(w, 403, "Wrong password", err)
+		return
+	}
+
+	ok, err := cfg.checkSecondFactor(r.Context(), qtx, user, params.Code, params.RecoveryCode)
+	if err != nil {
+		respondWithError(w, 500, "Error disabling two-factor authentication", err)
+		return
+	}
+	if !ok {
+		respondWithError(w, 403, "Invalid code", nil)
+		return
+	}
+
+	err = qtx.DisableTOTP(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, 500, "Error disabling two-factor authentication", err)
+		return
+	}
+
+	err = qtx.DeleteRecoveryCodes(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, 500, "Error disabling two-factor authentication", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error disabling two-factor authentication", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+// handlerLoginTwoFactor completes a login started at POST /api/login by
+// exchanging the challenge token and a TOTP or recovery code for a
+// session. A challenge works once and allows only a few wrong codes.
+func (cfg *apiConfig) handlerLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		ChallengeToken string `json:"challenge_token"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recovery_code"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	payload, err := auth.ValidateSignedToken(params.ChallengeToken, loginChallengePurpose, cfg.secret)
+	if err != nil {
+		respondWithError(w, 401, "Invalid or expired challenge", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Error logging in", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	challenge, err := qtx.GetLoginChallengeForUpdate(r.Context(), payload.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 401, "Invalid or expired challenge", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error logging in", err)
+		return
+	}
+	now := time.Now().UTC()
+	if challenge.UsedAt.Valid || challenge.UserID != payload.Subject || now.After(challenge.ExpiresAt) {
+		respondWithError(w, 401, "Invalid or expired challenge", nil)
+		return
+	}
+	if challenge.Attempts >= loginChallengeMaxAttempts {
+		respondWithError(w, 429, "Too many attempts, log in again", nil)
+		return
+	}
+
+	user, err := qtx.GetUser(r.Context(), challenge.UserID)
+	if err != nil {
+		respondWithError(w, 401, "Invalid or expired challenge", err)
+		return
+	}
+	if user.SuspendedAt.Valid {
+		respondWithError(w, 403, "Account suspended", nil)
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondWithError(w, 401, "Invalid or expired challenge", nil)
+		return
+	}
+
+	ok, err := cfg.checkSecondFactor(r.Context(), qtx, user, params.Code, params.RecoveryCode)
+	if err != nil {
+		respondWithError(w, 500, "Error logging in", err)
+		return
+	}
+	if !ok {
+		err = qtx.RecordLoginChallengeAttempt(r.Context(), challenge.ID)
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			respondWithError(w, 500, "Error logging in", err)
+			return
+		}
+		respondWithError(w, 401, "Invalid code", nil)
+		return
+	}
+
+	err = qtx.UseLoginChallenge(r.Context(), database.UseLoginChallengeParams{
+		UsedAt: sql.NullTime{Time: now, Valid: true},
+		ID:     challenge.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error logging in", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error logging in", err)
+		return
+	}
+
+	cfg.respondWithSession(w, r, user)
+}
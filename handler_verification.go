@@ -0,0 +1,151 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jradziejewski/chirpy/internal/auth"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+// handlerVerifyEmail marks an email address as verified. The token is the
+// proof, so no access token is needed. Each token works once, and only
+// while the account still has the address it was sent to.
+func (cfg *apiConfig) handlerVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		Token string `json:"token"`
+	}
+	params := parameters{}
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Error decoding JSON", err)
+		return
+	}
+
+	payload, err := auth.ValidateSignedToken(params.Token, emailVerificationPurpose, cfg.secret)
+	if err != nil {
+		respondWithError(w, 400, "Invalid or expired token", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Error verifying email", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	verification, err := qtx.GetEmailVerificationForUpdate(r.Context(), payload.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 400, "Invalid or expired token", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Error verifying email", err)
+		return
+	}
+	now := time.Now().UTC()
+	if verification.UsedAt.Valid || verification.UserID != payload.Subject || now.After(verification.ExpiresAt) {
+		respondWithError(w, 400, "Invalid or expired token", nil)
+		return
+	}
+
+	user, err := qtx.GetUser(r.Context(), verification.UserID)
+	if err != nil {
+		respondWithError(w, 500, "Error verifying email", err)
+		return
+	}
+	if user.Email != verification.Email {
+		respondWithError(w, 400, "Token was sent to a different email address", nil)
+		return
+	}
+
+	err = qtx.UseEmailVerification(r.Context(), database.UseEmailVerificationParams{
+		UsedAt: sql.NullTime{Time: now, Valid: true},
+		ID:     verification.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error verifying email", err)
+		return
+	}
+
+	err = qtx.VerifyUserEmail(r.Context(), database.VerifyUserEmailParams{
+		EmailVerifiedAt: sql.NullTime{Time: now, Valid: true},
+		ID:              user.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Error verifying email", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Error verifying email", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+// handlerResendVerification sends a fresh verification email to the
+// caller's current address. Links sent earlier stop working.
+func (cfg *apiConfig) handlerResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 401, "Unauthorized", err)
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		respondWithError(w, 409, "Email already verified", nil)
+		return
+	}
+
+	latest, err := cfg.db.GetLatestEmailVerification(r.Context(), userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 500, "Could not send verification email", err)
+		return
+	}
+	if err == nil && time.Since(latest.CreatedAt) < emailVerificationResendInterval {
+		respondWithError(w, 429, "Please wait before requesting another email", nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Could not send verification email", err)
+		return
+	}
+	defer tx.Rollback()
+
+	token, err := cfg.createEmailVerification(r.Context(), cfg.db.WithTx(tx), user)
+	if err != nil {
+		respondWithError(w, 500, "Could not send verification email", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, "Could not send verification email", err)
+		return
+	}
+
+	err = cfg.sendVerificationEmail(r.Context(), user.Email, token)
+	if err != nil {
+		respondWithError(w, 500, "Could not send verification email", err)
+		return
+	}
+
+	w.WriteHeader(204)
+}
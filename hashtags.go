@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+const maxHashtagLength = 50
+
+// A hashtag starts at the beginning of the body or after a character that
+// cannot be part of a word, so "a#b" and "&#39;" are not tags.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#])#([\p{L}\p{N}_]+)`)
+
+// extractHashtags returns the distinct, lowercased tags in body in the order
+// they first appear.
+func extractHashtags(body string) []string {
+	var tags []string
+	seen := map[string]bool{}
+
+	for _, match := range hashtagPattern.FindAllStringSubmatch(body, -1) {
+		tag := strings.ToLower(match[1])
+		if len([]rune(tag)) > maxHashtagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// indexHashtags replaces the hashtags stored for chirp with the ones in its
+// current body. It expects to run inside the transaction that wrote the body.
+func indexHashtags(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	err := q.DeleteChirpHashtags(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, tag := range extractHashtags(chirp.Body) {
+		hashtag, err := q.UpsertHashtag(ctx, database.UpsertHashtagParams{
+			ID:        uuid.New(),
+			Name:      tag,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.CreateChirpHashtag(ctx, database.CreateChirpHashtagParams{
+			ChirpID:   chirp.ID,
+			HashtagID: hashtag.ID,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -136,3 +137,104 @@ func TestGetBearerToken(t *testing.T) {
 		t.Fatalf(`GetBearerToken("Authorization": "Bear :)"): expected error, got no error`)
 	}
 }
+
+func TestHashToken(t *testing.T) {
+	token, err := MakeRefreshToken()
+	if err != nil {
+		t.Fatalf("MakeRefreshToken: expected no error, got %v", err)
+	}
+
+	hashed := HashToken(token)
+	if hashed == token {
+		t.Fatalf("HashToken(%s): expected a digest, got the token back", token)
+	}
+	if HashToken(token) != hashed {
+		t.Fatalf("HashToken(%s): expected the same digest twice", token)
+	}
+	if HashToken(token+"0") == hashed {
+		t.Fatalf("HashToken(%s): expected different tokens to hash differently", token)
+	}
+
+	// SHA-256 of the empty string.
+	expected := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if got := HashToken(""); got != expected {
+		t.Fatalf(`HashToken(""): expected %s, got %s`, expected, got)
+	}
+}
+
+func TestSignedToken(t *testing.T) {
+	userID := uuid.New()
+	tokenSecret := "supersecret"
+
+	tokenString, issued, err := MakeSignedToken("email-verification", userID, tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeSignedToken: expected no error, got %v", err)
+	}
+
+	payload, err := ValidateSignedToken(tokenString, "email-verification", tokenSecret)
+	if err != nil {
+		t.Fatalf("ValidateSignedToken(%v): expected no error, got %v", tokenString, err)
+	}
+	if payload.Subject != userID {
+		t.Fatalf("ValidateSignedToken(%v): expected subject %v, got %v", tokenString, userID, payload.Subject)
+	}
+	if payload.ID != issued.ID {
+		t.Fatalf("ValidateSignedToken(%v): expected ID %v, got %v", tokenString, issued.ID, payload.ID)
+	}
+
+	_, other, err := MakeSignedToken("email-verification", userID, tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeSignedToken: expected no error, got %v", err)
+	}
+	if other.ID == issued.ID {
+		t.Fatalf("MakeSignedToken: expected a new ID for every token, got %v twice", issued.ID)
+	}
+}
+
+func TestValidateSignedTokenRejects(t *testing.T) {
+	userID := uuid.New()
+	tokenSecret := "supersecret"
+
+	tokenString, _, err := MakeSignedToken("email-verification", userID, tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeSignedToken: expected no error, got %v", err)
+	}
+	expired, _, err := MakeSignedToken("email-verification", userID, tokenSecret, -time.Minute)
+	if err != nil {
+		t.Fatalf("MakeSignedToken: expected no error, got %v", err)
+	}
+	encoded, signature, _ := strings.Cut(tokenString, ".")
+	otherPayload, _, _ := strings.Cut(expired, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		purpose string
+		secret  string
+	}{
+		{name: "wrong secret", token: tokenString, purpose: "email-verification", secret: "wrong secret"},
+		{name: "wrong purpose", token: tokenString, purpose: "password-reset", secret: tokenSecret},
+		{name: "expired", token: expired, purpose: "email-verification", secret: tokenSecret},
+		{name: "swapped payload", token: otherPayload + "." + signature, purpose: "email-verification", secret: tokenSecret},
+		{name: "no signature", token: encoded, purpose: "email-verification", secret: tokenSecret},
+		{name: "access token", token: "", purpose: "email-verification", secret: tokenSecret},
+	}
+
+	accessToken, err := MakeJWT(userID, tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT: expected no error, got %v", err)
+	}
+	tests[len(tests)-1].token = accessToken
+
+	for _, test := range tests {
+		_, err := ValidateSignedToken(test.token, test.purpose, test.secret)
+		if err == nil {
+			t.Fatalf("ValidateSignedToken (%s): expected error, got no error", test.name)
+		}
+	}
+
+	_, err = ValidateJWT(tokenString, tokenSecret)
+	if err == nil {
+		t.Fatalf("ValidateJWT(%v): expected signed token to be rejected, got no error", tokenString)
+	}
+}
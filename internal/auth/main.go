@@ -2,6 +2,7 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"net/http"
@@ -90,6 +91,14 @@ func MakeRefreshToken() (string, error) {
 	return hex.EncodeToString(byteSlice), nil
 }
 
+// HashToken digests a random token, such as one from MakeRefreshToken, for
+// storage. Such tokens carry enough entropy that a fast, unsalted hash is
+// safe, unlike passwords.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func GetAPIKey(headers http.Header) (string, error) {
 	authorization := headers.Get("Authorization")
 	if authorization == "" {
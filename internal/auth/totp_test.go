@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"hash"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238, Appendix B.
+func TestTOTPRFCVectors(t *testing.T) {
+	seeds := map[string]struct {
+		key []byte
+		h   func() hash.Hash
+	}{
+		"SHA1":   {key: []byte("12345678901234567890"), h: sha1.New},
+		"SHA256": {key: []byte("12345678901234567890123456789012"), h: sha256.New},
+		"SHA512": {key: []byte("1234567890123456789012345678901234567890123456789012345678901234"), h: sha512.New},
+	}
+
+	tests := []struct {
+		unix     int64
+		mode     string
+		expected string
+	}{
+		{unix: 59, mode: "SHA1", expected: "94287082"},
+		{unix: 59, mode: "SHA256", expected: "46119246"},
+		{unix: 59, mode: "SHA512", expected: "90693936"},
+		{unix: 1111111109, mode: "SHA1", expected: "07081804"},
+		{unix: 1111111109, mode: "SHA256", expected: "68084774"},
+		{unix: 1111111109, mode: "SHA512", expected: "25091201"},
+		{unix: 1111111111, mode: "SHA1", expected: "14050471"},
+		{unix: 1111111111, mode: "SHA256", expected: "67062674"},
+		{unix: 1111111111, mode: "SHA512", expected: "99943326"},
+		{unix: 1234567890, mode: "SHA1", expected: "89005924"},
+		{unix: 1234567890, mode: "SHA256", expected: "91819424"},
+		{unix: 1234567890, mode: "SHA512", expected: "93441116"},
+		{unix: 2000000000, mode: "SHA1", expected: "69279037"},
+		{unix: 2000000000, mode: "SHA256", expected: "90698825"},
+		{unix: 2000000000, mode: "SHA512", expected: "38618901"},
+		{unix: 20000000000, mode: "SHA1", expected: "65353130"},
+		{unix: 20000000000, mode: "SHA256", expected: "77737706"},
+		{unix: 20000000000, mode: "SHA512", expected: "47863826"},
+	}
+
+	for _, test := range tests {
+		seed := seeds[test.mode]
+		step := totpStep(time.Unix(test.unix, 0), 30*time.Second)
+		code := hotp(seed.key, uint64(step), 8, seed.h)
+		if code != test.expected {
+			t.Fatalf("TOTP %s at %d: expected %s, got %s", test.mode, test.unix, test.expected, code)
+		}
+	}
+}
+
+func TestGenerateAndValidateTOTP(t *testing.T) {
+	secret := base32NoPadding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+
+	code, err := GenerateTOTP(secret, now)
+	if err != nil {
+		t.Fatalf("GenerateTOTP: expected no error, got %v", err)
+	}
+	// The last six digits of the RFC 6238 SHA1 vector.
+	if code != "081804" {
+		t.Fatalf("GenerateTOTP: expected %s, got %s", "081804", code)
+	}
+
+	step, ok := ValidateTOTP(secret, code, now)
+	if !ok {
+		t.Fatalf("ValidateTOTP(%s): expected code to be accepted", code)
+	}
+	if step != 1111111109/30 {
+		t.Fatalf("ValidateTOTP(%s): expected step %d, got %d", code, 1111111109/30, step)
+	}
+
+	_, ok = ValidateTOTP(secret, code, now.Add(30*time.Second))
+	if !ok {
+		t.Fatalf("ValidateTOTP(%s): expected code from the previous period to be accepted", code)
+	}
+	_, ok = ValidateTOTP(secret, code, now.Add(90*time.Second))
+	if ok {
+		t.Fatalf("ValidateTOTP(%s): expected code from three periods ago to be rejected", code)
+	}
+
+	for _, bad := range []string{"", "12345", "1234567", "000000"} {
+		if _, ok := ValidateTOTP(secret, bad, now); ok {
+			t.Fatalf("ValidateTOTP(%q): expected code to be rejected", bad)
+		}
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: expected no error, got %v", err)
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: expected base32, got %q: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Fatalf("GenerateTOTPSecret: expected 20 bytes, got %d", len(key))
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("JBSWY3DPEHPK3PXP", "Chirpy", "user@example.com")
+
+	for _, want := range []string{"otpauth://totp/Chirpy:user@example.com?", "secret=JBSWY3DPEHPK3PXP", "issuer=Chirpy", "digits=6", "period=30"} {
+		if !strings.Contains(uri, want) {
+			t.Fatalf("TOTPURI: expected %q in %q", want, uri)
+		}
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes: expected no error, got %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes: expected 10 codes, got %d", len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if seen[code] {
+			t.Fatalf("GenerateRecoveryCodes: got %s twice", code)
+		}
+		seen[code] = true
+		if NormalizeRecoveryCode(code) != code {
+			t.Fatalf("NormalizeRecoveryCode(%s): expected the code unchanged, got %s", code, NormalizeRecoveryCode(code))
+		}
+		typed := " " + strings.ToUpper(strings.ReplaceAll(code, "-", "")) + " "
+		if NormalizeRecoveryCode(typed) != code {
+			t.Fatalf("NormalizeRecoveryCode(%q): expected %s, got %s", typed, code, NormalizeRecoveryCode(typed))
+		}
+	}
+}
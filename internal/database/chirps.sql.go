@@ -7,29 +7,45 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-insert into chirps (id, created_at, updated_at, body, user_id)
+insert into chirps (id, created_at, updated_at, body, user_id, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, status)
 values (
 	$1,
 	$2,
 	$3,
 	$4,
-	$5
+	$5,
+	$6,
+	$7,
+	$8,
+	$9,
+	$10,
+	$11,
+	$12
 )
-returning id, created_at, updated_at, body, user_id
+returning id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status
 `
 
 type CreateChirpParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.UUID
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Body       string
+	UserID     uuid.UUID
+	InReplyTo  uuid.NullUUID
+	RootID     uuid.NullUUID
+	RechirpOf  uuid.NullUUID
+	QuoteOf    uuid.NullUUID
+	IsQuote    bool
+	Visibility string
+	Status     string
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
@@ -39,6 +55,13 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		arg.UpdatedAt,
 		arg.Body,
 		arg.UserID,
+		arg.InReplyTo,
+		arg.RootID,
+		arg.RechirpOf,
+		arg.QuoteOf,
+		arg.IsQuote,
+		arg.Visibility,
+		arg.Status,
 	)
 	var i Chirp
 	err := row.Scan(
@@ -47,31 +70,91 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.IsQuote,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
-const deleteChirp = `-- name: DeleteChirp :exec
+const deleteChirps = `-- name: DeleteChirps :exec
 delete from chirps
-where id = $1
 `
 
-func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirp, id)
+func (q *Queries) DeleteChirps(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteChirps)
 	return err
 }
 
-const deleteChirps = `-- name: DeleteChirps :exec
+const deleteRechirp = `-- name: DeleteRechirp :exec
 delete from chirps
+where user_id = $1 and rechirp_of = $2
 `
 
-func (q *Queries) DeleteChirps(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteChirps)
+type DeleteRechirpParams struct {
+	UserID    uuid.UUID
+	RechirpOf uuid.NullUUID
+}
+
+func (q *Queries) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRechirp, arg.UserID, arg.RechirpOf)
 	return err
 }
 
+const getAllChirpsByUser = `-- name: GetAllChirpsByUser :many
+select id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status from chirps
+where user_id = $1
+order by created_at, id
+`
+
+// Every chirp the user ever wrote, including deleted and moderated ones, for
+// data exports.
+func (q *Queries) GetAllChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getAllChirpsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.IsQuote,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirp = `-- name: GetChirp :one
-select id, created_at, updated_at, body, user_id from chirps
+select id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status from chirps
 where id = $1
 `
 
@@ -84,18 +167,202 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.IsQuote,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
-const getChirps = `-- name: GetChirps :many
-select id, created_at, updated_at, body, user_id from chirps
-where coalesce($1, user_id) is null or user_id =coalesce($1, user_id) 
-order by created_at
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+select id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status from chirps
+where id = $1
+for update
+`
+
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.IsQuote,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getChirpThread = `-- name: GetChirpThread :many
+with recursive thread(id, depth) as (
+	select id, 0 from chirps
+	where id = $1
+	and status = 'published'
+	and chirp_visible_to(id, user_id, visibility, $2::uuid)
+	union all
+	select c.id, t.depth + 1 from chirps c
+	inner join thread t on c.in_reply_to = t.id
+	where c.status = 'published'
+	and chirp_visible_to(c.id, c.user_id, c.visibility, $2::uuid)
+)
+select chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.root_id, chirps.rechirp_of, chirps.quote_of, chirps.is_quote, chirps.visibility, chirps.deleted_at, chirps.status, thread.depth
+from thread
+inner join chirps on chirps.id = thread.id
+order by thread.depth, chirps.created_at, chirps.id
+`
+
+type GetChirpThreadParams struct {
+	RootID   uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+type GetChirpThreadRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+// Deleted chirps are kept so their replies stay attached to the thread.
+func (q *Queries) GetChirpThread(ctx context.Context, arg GetChirpThreadParams) ([]GetChirpThreadRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpThread, arg.RootID, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpThreadRow
+	for rows.Next() {
+		var i GetChirpThreadRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.RootID,
+			&i.Chirp.RechirpOf,
+			&i.Chirp.QuoteOf,
+			&i.Chirp.IsQuote,
+			&i.Chirp.Visibility,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.Status,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsAsc = `-- name: GetChirpsAsc :many
+select id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status from chirps
+where ($1::uuid is null or user_id = $1::uuid)
+and (
+	$2::timestamp is null
+	or (created_at, id) > ($2::timestamp, $3::uuid)
+)
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, $4::uuid)
+and ($1::uuid is not null or not author_muted_by(user_id, $4::uuid))
+and ($5::uuid is null or id <> $5::uuid)
+order by created_at, id
+limit $6
+`
+
+type GetChirpsAscParams struct {
+	UserID          uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	ExcludeID       uuid.NullUUID
+	Limit           int32
+}
+
+// Muted authors are left out unless the listing asks for that author.
+// exclude_id leaves out a chirp listed elsewhere, such as a pinned one.
+func (q *Queries) GetChirpsAsc(ctx context.Context, arg GetChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsAsc,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.ExcludeID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.IsQuote,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+select id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status from chirps
+where id = any($1::uuid[])
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, $2::uuid)
 `
 
-func (q *Queries) GetChirps(ctx context.Context, userID interface{}) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps, userID)
+type GetChirpsByIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+// Deleted chirps are included so quotes of them can be shown as tombstones.
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(arg.Ids), arg.ViewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -109,6 +376,15 @@ func (q *Queries) GetChirps(ctx context.Context, userID interface{}) ([]Chirp, e
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.IsQuote,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -122,3 +398,474 @@ func (q *Queries) GetChirps(ctx context.Context, userID interface{}) ([]Chirp, e
 	}
 	return items, nil
 }
+
+const getChirpsDesc = `-- name: GetChirpsDesc :many
+select id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status from chirps
+where ($1::uuid is null or user_id = $1::uuid)
+and (
+	$2::timestamp is null
+	or (created_at, id) < ($2::timestamp, $3::uuid)
+)
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, $4::uuid)
+and ($1::uuid is not null or not author_muted_by(user_id, $4::uuid))
+and ($5::uuid is null or id <> $5::uuid)
+order by created_at desc, id desc
+limit $6
+`
+
+type GetChirpsDescParams struct {
+	UserID          uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	ExcludeID       uuid.NullUUID
+	Limit           int32
+}
+
+// Muted authors are left out unless the listing asks for that author.
+// exclude_id leaves out a chirp listed elsewhere, such as a pinned one.
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsDesc,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.ExcludeID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.IsQuote,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRechirp = `-- name: GetRechirp :one
+select id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status from chirps
+where user_id = $1 and rechirp_of = $2
+`
+
+type GetRechirpParams struct {
+	UserID    uuid.UUID
+	RechirpOf uuid.NullUUID
+}
+
+func (q *Queries) GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getRechirp, arg.UserID, arg.RechirpOf)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.IsQuote,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getTimeline = `-- name: GetTimeline :many
+select chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.root_id, chirps.rechirp_of, chirps.quote_of, chirps.is_quote, chirps.visibility, chirps.deleted_at, chirps.status from chirps
+inner join follows on follows.followee_id = chirps.user_id
+where follows.follower_id = $1
+and (
+	$2::timestamp is null
+	or (chirps.created_at, chirps.id) < ($2::timestamp, $3::uuid)
+)
+and chirps.deleted_at is null
+and chirps.status = 'published'
+and chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, $1)
+and not author_muted_by(chirps.user_id, $1)
+order by chirps.created_at desc, chirps.id desc
+limit $4
+`
+
+type GetTimelineParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetTimeline(ctx context.Context, arg GetTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getTimeline,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.InReplyTo,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.IsQuote,
+			&i.Visibility,
+			&i.DeletedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVisibleChirp = `-- name: GetVisibleChirp :one
+select id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status from chirps
+where id = $1
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, $2::uuid)
+`
+
+type GetVisibleChirpParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+// Reads a chirp on behalf of a viewer. Chirps the viewer may not see are
+// reported as missing so their existence is not leaked.
+func (q *Queries) GetVisibleChirp(ctx context.Context, arg GetVisibleChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getVisibleChirp, arg.ID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.IsQuote,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getVisibleChirpIDs = `-- name: GetVisibleChirpIDs :many
+select id from chirps
+where id = any($1::uuid[])
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, $2::uuid)
+`
+
+type GetVisibleChirpIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+// Narrows ids down to the chirps the viewer may read.
+func (q *Queries) GetVisibleChirpIDs(ctx context.Context, arg GetVisibleChirpIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getVisibleChirpIDs, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const holdChirp = `-- name: HoldChirp :exec
+update chirps
+set status = 'held'
+where id = $1
+and status = 'published'
+`
+
+func (q *Queries) HoldChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, holdChirp, id)
+	return err
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+delete from chirps
+where deleted_at < $1::timestamp
+and not exists (
+	select 1 from chirps replies
+	where replies.in_reply_to = chirps.id
+	or replies.root_id = chirps.id
+)
+`
+
+// Chirps that still have replies are kept as tombstones, so the thread
+// below them stays in one piece; see StripDeletedChirps.
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :exec
+update chirps
+set deleted_at = null
+where (id = $1 or rechirp_of = $1)
+and deleted_at = $2::timestamp
+`
+
+type RestoreChirpParams struct {
+	ID        uuid.UUID
+	DeletedAt time.Time
+}
+
+// Brings back a deleted chirp and the rechirps that were deleted with it.
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) error {
+	_, err := q.db.ExecContext(ctx, restoreChirp, arg.ID, arg.DeletedAt)
+	return err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+select
+	chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.in_reply_to, chirps.root_id, chirps.rechirp_of, chirps.quote_of, chirps.is_quote, chirps.visibility, chirps.deleted_at, chirps.status,
+	ts_rank(search_vector, to_tsquery('english', $1))::float8 as rank,
+	ts_headline(
+		'english',
+		replace(replace(replace(replace(replace(body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+		to_tsquery('english', $1),
+		'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'
+	)::text as snippet
+from chirps
+where search_vector @@ to_tsquery('english', $1)
+and ($2::uuid is null or user_id = $2::uuid)
+and (
+	$3::float8 is null
+	or (ts_rank(search_vector, to_tsquery('english', $1))::float8, id)
+		< ($3::float8, $4::uuid)
+)
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, $5::uuid)
+and ($2::uuid is not null or not author_muted_by(user_id, $5::uuid))
+order by rank desc, id desc
+limit $6
+`
+
+type SearchChirpsParams struct {
+	Query      string
+	UserID     uuid.NullUUID
+	CursorRank sql.NullFloat64
+	CursorID   uuid.NullUUID
+	ViewerID   uuid.NullUUID
+	Limit      int32
+}
+
+type SearchChirpsRow struct {
+	Chirp   Chirp
+	Rank    float64
+	Snippet string
+}
+
+// Muted authors are left out unless the listing asks for that author.
+// The snippet is HTML: the body is escaped before the matches are wrapped
+// in <mark>, so it is safe to render as markup.
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Query,
+		arg.UserID,
+		arg.CursorRank,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.RootID,
+			&i.Chirp.RechirpOf,
+			&i.Chirp.QuoteOf,
+			&i.Chirp.IsQuote,
+			&i.Chirp.Visibility,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.Status,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :exec
+update chirps
+set deleted_at = $1::timestamp
+where (id = $2 or rechirp_of = $2)
+and deleted_at is null
+`
+
+type SoftDeleteChirpParams struct {
+	DeletedAt time.Time
+	ID        uuid.UUID
+}
+
+// Rechirps of a deleted chirp are hidden along with it.
+func (q *Queries) SoftDeleteChirp(ctx context.Context, arg SoftDeleteChirpParams) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirp, arg.DeletedAt, arg.ID)
+	return err
+}
+
+const stripDeletedChirps = `-- name: StripDeletedChirps :execrows
+update chirps
+set body = ''
+where deleted_at < $1::timestamp
+and body <> ''
+`
+
+// Empties the body of deleted chirps that are kept as tombstones.
+func (q *Queries) StripDeletedChirps(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, stripDeletedChirps, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+update chirps
+set body = $1, updated_at = $2
+where id = $3
+returning id, created_at, updated_at, body, user_id, search_vector, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, deleted_at, status
+`
+
+type UpdateChirpBodyParams struct {
+	Body      string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.Body, arg.UpdatedAt, arg.ID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.InReplyTo,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.IsQuote,
+		&i.Visibility,
+		&i.DeletedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const updateChirpStatus = `-- name: UpdateChirpStatus :exec
+update chirps
+set status = $1
+where id = $2 or rechirp_of = $2
+`
+
+type UpdateChirpStatusParams struct {
+	Status string
+	ID     uuid.UUID
+}
+
+// Rechirps follow the status of the chirp they share.
+func (q *Queries) UpdateChirpStatus(ctx context.Context, arg UpdateChirpStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateChirpStatus, arg.Status, arg.ID)
+	return err
+}
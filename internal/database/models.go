@@ -11,12 +11,177 @@ import (
 	"github.com/google/uuid"
 )
 
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
+type Bookmark struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	SearchVector interface{}
+	InReplyTo    uuid.NullUUID
+	RootID       uuid.NullUUID
+	RechirpOf    uuid.NullUUID
+	QuoteOf      uuid.NullUUID
+	IsQuote      bool
+	Visibility   string
+	DeletedAt    sql.NullTime
+	Status       string
+}
+
+type ChirpAttachment struct {
+	ID              uuid.UUID
+	ChirpID         uuid.UUID
+	Position        int32
+	FileName        string
+	ThumbnailName   string
+	ContentType     string
+	Width           int32
+	Height          int32
+	ThumbnailWidth  int32
+	ThumbnailHeight int32
+	AltText         string
+	CreatedAt       time.Time
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	HashtagID uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpMention struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	Length      int32
+	CreatedAt   time.Time
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	Body       string
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+}
+
+type Draft struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Body       string
+	InReplyTo  uuid.NullUUID
+	QuoteOf    uuid.NullUUID
+	Visibility string
+}
+
+type EmailVerification struct {
 	ID        uuid.UUID
+	UserID    uuid.UUID
+	Email     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
+type LoginChallenge struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Attempts  int32
+	UsedAt    sql.NullTime
+}
+
+type ModerationAction struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	ModeratorID  uuid.NullUUID
+	Action       string
+	ChirpID      uuid.NullUUID
+	TargetUserID uuid.NullUUID
+	Note         string
+}
+
+type ModerationRule struct {
+	ID        uuid.UUID
+	Term      string
+	Action    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type PasswordReset struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type Poll struct {
+	ID        uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+	ClosesAt  time.Time
+}
+
+type PollOption struct {
+	ID       uuid.UUID
+	PollID   uuid.UUID
+	Position int32
+	Label    string
+}
+
+type PollVote struct {
+	PollID    uuid.UUID
 	UserID    uuid.UUID
+	OptionID  uuid.UUID
+	CreatedAt time.Time
+}
+
+type RecoveryCode struct {
+	CodeHash  string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	UsedAt    sql.NullTime
 }
 
 type RefreshToken struct {
@@ -28,11 +193,46 @@ type RefreshToken struct {
 	UserID    uuid.UUID
 }
 
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ChirpID    uuid.UUID
+	ReporterID uuid.UUID
+	Reason     string
+	Details    string
+	Status     string
+}
+
+type ScheduledChirp struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Body       string
+	InReplyTo  uuid.NullUUID
+	QuoteOf    uuid.NullUUID
+	PublishAt  time.Time
+	Visibility string
+	FailedAt   sql.NullTime
+	Failure    sql.NullString
+}
+
 type User struct {
-	ID             uuid.UUID
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword sql.NullString
-	IsChirpyRed    sql.NullBool
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Email           string
+	HashedPassword  sql.NullString
+	IsChirpyRed     sql.NullBool
+	Username        sql.NullString
+	DisplayName     string
+	Bio             string
+	PinnedChirpID   uuid.NullUUID
+	IsAdmin         bool
+	SuspendedAt     sql.NullTime
+	EmailVerifiedAt sql.NullTime
+	TotpSecret      sql.NullString
+	TotpEnabledAt   sql.NullTime
+	TotpLastStep    sql.NullInt64
 }
@@ -80,6 +80,42 @@ func (q *Queries) GetRefreshToken(ctx context.Context, token string) (RefreshTok
 	return i, err
 }
 
+const getUserRefreshTokens = `-- name: GetUserRefreshTokens :many
+select token, created_at, updated_at, expires_at, revoked_at, user_id from refresh_tokens
+where user_id = $1
+order by created_at
+`
+
+func (q *Queries) GetUserRefreshTokens(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	rows, err := q.db.QueryContext(ctx, getUserRefreshTokens, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.Token,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const revokeToken = `-- name: RevokeToken :exec
 update refresh_tokens
 set revoked_at = $1, updated_at = $1
@@ -95,3 +131,20 @@ func (q *Queries) RevokeToken(ctx context.Context, arg RevokeTokenParams) error
 	_, err := q.db.ExecContext(ctx, revokeToken, arg.RevokedAt, arg.Token)
 	return err
 }
+
+const revokeUserTokens = `-- name: RevokeUserTokens :exec
+update refresh_tokens
+set revoked_at = $1, updated_at = $1
+where user_id = $2
+and revoked_at is null
+`
+
+type RevokeUserTokensParams struct {
+	RevokedAt sql.NullTime
+	UserID    uuid.UUID
+}
+
+func (q *Queries) RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error {
+	_, err := q.db.ExecContext(ctx, revokeUserTokens, arg.RevokedAt, arg.UserID)
+	return err
+}
@@ -11,18 +11,36 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const clearPinnedChirp = `-- name: ClearPinnedChirp :exec
+update users
+set pinned_chirp_id = null, updated_at = NOW()
+where pinned_chirp_id in (
+	select id from chirps
+	where id = $1 or rechirp_of = $1
+)
+`
+
+// Unpins a deleted chirp, and the rechirps deleted with it, from every
+// profile. Soft deletes do not fire the foreign key's on delete set null.
+func (q *Queries) ClearPinnedChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearPinnedChirp, id)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
-insert into users (id, created_at, updated_at, email, hashed_password)
+insert into users (id, created_at, updated_at, email, hashed_password, username)
 values (
 	$1,
 	$2,
 	$3,
 	$4,
-	$5
+	$5,
+	$6
 )
-returning id, created_at, updated_at, email, hashed_password, is_chirpy_red
+returning id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step
 `
 
 type CreateUserParams struct {
@@ -31,6 +49,7 @@ type CreateUserParams struct {
 	UpdatedAt      time.Time
 	Email          string
 	HashedPassword sql.NullString
+	Username       sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -40,6 +59,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.UpdatedAt,
 		arg.Email,
 		arg.HashedPassword,
+		arg.Username,
 	)
 	var i User
 	err := row.Scan(
@@ -49,10 +69,34 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+delete from users
+where id = $1
+`
+
+// Everything the user owns goes with them through on delete cascade.
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteUsers = `-- name: DeleteUsers :exec
 DELETE FROM users
 `
@@ -62,8 +106,65 @@ func (q *Queries) DeleteUsers(ctx context.Context) error {
 	return err
 }
 
+const disableTOTP = `-- name: DisableTOTP :exec
+update users
+set totp_secret = null, totp_enabled_at = null, totp_last_step = null, updated_at = NOW()
+where id = $1
+`
+
+func (q *Queries) DisableTOTP(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, disableTOTP, id)
+	return err
+}
+
+const enableTOTP = `-- name: EnableTOTP :exec
+update users
+set totp_enabled_at = $1, totp_last_step = $2, updated_at = $1
+where id = $3
+`
+
+type EnableTOTPParams struct {
+	TotpEnabledAt sql.NullTime
+	TotpLastStep  sql.NullInt64
+	ID            uuid.UUID
+}
+
+func (q *Queries) EnableTOTP(ctx context.Context, arg EnableTOTPParams) error {
+	_, err := q.db.ExecContext(ctx, enableTOTP, arg.TotpEnabledAt, arg.TotpLastStep, arg.ID)
+	return err
+}
+
+const getUser = `-- name: GetUser :one
+select id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step from users
+where id = $1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-select id, created_at, updated_at, email, hashed_password, is_chirpy_red from users
+select id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step from users
 where email = $1
 `
 
@@ -77,12 +178,51 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+select id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step from users
+where lower(username) = lower($1)
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
 const getUserFromRefreshToken = `-- name: GetUserFromRefreshToken :one
-select id, u.created_at, u.updated_at, email, hashed_password, is_chirpy_red, token, r.created_at, r.updated_at, expires_at, revoked_at, user_id from users u
+select id, u.created_at, u.updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, token, r.created_at, r.updated_at, expires_at, revoked_at, user_id from users u
 inner join refresh_tokens r
 on r.user_id = u.id
 where r.token = $1
@@ -91,18 +231,28 @@ and revoked_at is null
 `
 
 type GetUserFromRefreshTokenRow struct {
-	ID             uuid.UUID
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword sql.NullString
-	IsChirpyRed    sql.NullBool
-	Token          string
-	CreatedAt_2    time.Time
-	UpdatedAt_2    time.Time
-	ExpiresAt      time.Time
-	RevokedAt      sql.NullTime
-	UserID         uuid.UUID
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Email           string
+	HashedPassword  sql.NullString
+	IsChirpyRed     sql.NullBool
+	Username        sql.NullString
+	DisplayName     string
+	Bio             string
+	PinnedChirpID   uuid.NullUUID
+	IsAdmin         bool
+	SuspendedAt     sql.NullTime
+	EmailVerifiedAt sql.NullTime
+	TotpSecret      sql.NullString
+	TotpEnabledAt   sql.NullTime
+	TotpLastStep    sql.NullInt64
+	Token           string
+	CreatedAt_2     time.Time
+	UpdatedAt_2     time.Time
+	ExpiresAt       time.Time
+	RevokedAt       sql.NullTime
+	UserID          uuid.UUID
 }
 
 func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (GetUserFromRefreshTokenRow, error) {
@@ -115,6 +265,16 @@ func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (Ge
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
 		&i.Token,
 		&i.CreatedAt_2,
 		&i.UpdatedAt_2,
@@ -125,11 +285,138 @@ func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (Ge
 	return i, err
 }
 
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+select id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step from users
+where id = any($1::uuid[])
+`
+
+func (q *Queries) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Username,
+			&i.DisplayName,
+			&i.Bio,
+			&i.PinnedChirpID,
+			&i.IsAdmin,
+			&i.SuspendedAt,
+			&i.EmailVerifiedAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersByUsernames = `-- name: GetUsersByUsernames :many
+select id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step from users
+where lower(username) = any($1::text[])
+`
+
+func (q *Queries) GetUsersByUsernames(ctx context.Context, usernames []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByUsernames, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Username,
+			&i.DisplayName,
+			&i.Bio,
+			&i.PinnedChirpID,
+			&i.IsAdmin,
+			&i.SuspendedAt,
+			&i.EmailVerifiedAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setTOTPSecret = `-- name: SetTOTPSecret :exec
+update users
+set totp_secret = $1, totp_enabled_at = null, totp_last_step = null, updated_at = NOW()
+where id = $2
+`
+
+type SetTOTPSecretParams struct {
+	TotpSecret sql.NullString
+	ID         uuid.UUID
+}
+
+// Starts (or restarts) enrollment. 2FA stays off until EnableTOTP.
+func (q *Queries) SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) error {
+	_, err := q.db.ExecContext(ctx, setTOTPSecret, arg.TotpSecret, arg.ID)
+	return err
+}
+
+const suspendUser = `-- name: SuspendUser :exec
+update users
+set suspended_at = $1, updated_at = $1
+where id = $2
+`
+
+type SuspendUserParams struct {
+	SuspendedAt sql.NullTime
+	ID          uuid.UUID
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) error {
+	_, err := q.db.ExecContext(ctx, suspendUser, arg.SuspendedAt, arg.ID)
+	return err
+}
+
 const updateEmailAndPassword = `-- name: UpdateEmailAndPassword :one
 update users
-set email = $1, hashed_password = $2, updated_at = NOW()
+set
+	email = $1,
+	hashed_password = $2,
+	email_verified_at = case when email = $1 then email_verified_at end,
+	updated_at = NOW()
 where id = $3
-returning id, created_at, updated_at, email, hashed_password, is_chirpy_red
+returning id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step
 `
 
 type UpdateEmailAndPasswordParams struct {
@@ -138,6 +425,7 @@ type UpdateEmailAndPasswordParams struct {
 	ID             uuid.UUID
 }
 
+// Changing the email address marks it unverified again.
 func (q *Queries) UpdateEmailAndPassword(ctx context.Context, arg UpdateEmailAndPasswordParams) (User, error) {
 	row := q.db.QueryRowContext(ctx, updateEmailAndPassword, arg.Email, arg.HashedPassword, arg.ID)
 	var i User
@@ -148,6 +436,16 @@ func (q *Queries) UpdateEmailAndPassword(ctx context.Context, arg UpdateEmailAnd
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
@@ -156,7 +454,7 @@ const updateIsChirpyRed = `-- name: UpdateIsChirpyRed :one
 update users
 set is_chirpy_red = $1, updated_at = NOW()
 where id = $2
-returning id, created_at, updated_at, email, hashed_password, is_chirpy_red
+returning id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step
 `
 
 type UpdateIsChirpyRedParams struct {
@@ -174,6 +472,152 @@ func (q *Queries) UpdateIsChirpyRed(ctx context.Context, arg UpdateIsChirpyRedPa
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updatePassword = `-- name: UpdatePassword :exec
+update users
+set hashed_password = $1, updated_at = NOW()
+where id = $2
+`
+
+type UpdatePasswordParams struct {
+	HashedPassword sql.NullString
+	ID             uuid.UUID
+}
+
+func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updatePassword, arg.HashedPassword, arg.ID)
+	return err
+}
+
+const updatePinnedChirp = `-- name: UpdatePinnedChirp :one
+update users
+set pinned_chirp_id = $1, updated_at = NOW()
+where id = $2
+returning id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step
+`
+
+type UpdatePinnedChirpParams struct {
+	PinnedChirpID uuid.NullUUID
+	ID            uuid.UUID
+}
+
+func (q *Queries) UpdatePinnedChirp(ctx context.Context, arg UpdatePinnedChirpParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updatePinnedChirp, arg.PinnedChirpID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updateProfile = `-- name: UpdateProfile :one
+update users
+set
+	username = coalesce($1, username),
+	display_name = coalesce($2, display_name),
+	bio = coalesce($3, bio),
+	updated_at = NOW()
+where id = $4
+returning id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, pinned_chirp_id, is_admin, suspended_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step
+`
+
+type UpdateProfileParams struct {
+	Username    sql.NullString
+	DisplayName sql.NullString
+	Bio         sql.NullString
+	ID          uuid.UUID
+}
+
+func (q *Queries) UpdateProfile(ctx context.Context, arg UpdateProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateProfile,
+		arg.Username,
+		arg.DisplayName,
+		arg.Bio,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
+
+const updateTOTPLastStep = `-- name: UpdateTOTPLastStep :execrows
+update users
+set totp_last_step = $1
+where id = $2
+and (totp_last_step is null or totp_last_step < $1)
+`
+
+type UpdateTOTPLastStepParams struct {
+	Step sql.NullInt64
+	ID   uuid.UUID
+}
+
+// Only moves forward, so one code cannot complete two logins.
+func (q *Queries) UpdateTOTPLastStep(ctx context.Context, arg UpdateTOTPLastStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateTOTPLastStep, arg.Step, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :exec
+update users
+set email_verified_at = $1, updated_at = $1
+where id = $2
+`
+
+type VerifyUserEmailParams struct {
+	EmailVerifiedAt sql.NullTime
+	ID              uuid.UUID
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) error {
+	_, err := q.db.ExecContext(ctx, verifyUserEmail, arg.EmailVerifiedAt, arg.ID)
+	return err
+}
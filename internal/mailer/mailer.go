@@ -0,0 +1,55 @@
+// Package mailer sends the emails the server needs, such as verification
+// links, through a swappable backend.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages. Send returning nil means the message was handed
+// off, not that it reached the recipient.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogMailer writes every message to w instead of delivering it. It is meant
+// for local development, where w is usually stdout or a file that can be
+// read to follow links.
+type LogMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewLogMailer(w io.Writer, from string) *LogMailer {
+	return &LogMailer{w: w, from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("mail headers must not contain line breaks")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.w, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n.\r\n",
+		m.from,
+		msg.To,
+		msg.Subject,
+		time.Now().UTC().Format(time.RFC1123Z),
+		msg.Body,
+	)
+	return err
+}
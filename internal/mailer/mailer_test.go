@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLogMailerSend(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewLogMailer(&buf, "chirpy@localhost")
+
+	err := m.Send(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Verify your email",
+		Body:    "token: abc",
+	})
+	if err != nil {
+		t.Fatalf("Send: expected no error, got %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"From: chirpy@localhost\r\n", "To: user@example.com\r\n", "Subject: Verify your email\r\n", "\r\n\r\ntoken: abc\r\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("Send: expected output to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestLogMailerRejectsHeaderInjection(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewLogMailer(&buf, "chirpy@localhost")
+
+	err := m.Send(context.Background(), Message{
+		To:      "user@example.com\r\nBcc: victim@example.com",
+		Subject: "Verify your email",
+	})
+	if err == nil {
+		t.Fatalf("Send: expected error, got no error")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("Send: expected nothing written, got %q", buf.String())
+	}
+}
@@ -0,0 +1,173 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	// MaxImageBytes caps the size of a single uploaded image.
+	MaxImageBytes = 5 << 20
+	// maxPixels guards against decompression bombs, which are small files
+	// that decode into huge bitmaps.
+	maxPixels = 40_000_000
+	// ThumbnailSize is the longest edge of a generated thumbnail.
+	ThumbnailSize = 320
+	jpegQuality   = 85
+)
+
+var (
+	ErrTooLarge        = errors.New("image is too large")
+	ErrUnsupportedType = errors.New("unsupported image type")
+)
+
+// Image is an encoded image along with its dimensions.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Processed holds the sanitised original and its thumbnail.
+type Processed struct {
+	Original  Image
+	Thumbnail Image
+}
+
+// Process validates an upload by sniffing its content rather than trusting
+// the client supplied type, then decodes and re-encodes it. Re-encoding drops
+// every metadata segment, EXIF included. GIFs keep only their first frame and
+// are stored as PNG.
+func Process(data []byte) (Processed, error) {
+	if len(data) > MaxImageBytes {
+		return Processed{}, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	var decode func([]byte) (image.Image, error)
+	switch contentType {
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	case "image/gif":
+		decode = func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) }
+	default:
+		return Processed{}, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return Processed{}, ErrTooLarge
+	}
+
+	img, err := decode(data)
+	if err != nil {
+		return Processed{}, err
+	}
+
+	original, err := encode(img, contentType == "image/jpeg")
+	if err != nil {
+		return Processed{}, err
+	}
+
+	thumbnail, err := encode(Thumbnail(img, ThumbnailSize), contentType == "image/jpeg")
+	if err != nil {
+		return Processed{}, err
+	}
+
+	return Processed{
+		Original:  original,
+		Thumbnail: thumbnail,
+	}, nil
+}
+
+func encode(img image.Image, asJPEG bool) (Image, error) {
+	var buf bytes.Buffer
+	out := Image{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	if asJPEG {
+		err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return Image{}, err
+		}
+		out.ContentType = "image/jpeg"
+		out.Ext = ".jpg"
+	} else {
+		err := png.Encode(&buf, img)
+		if err != nil {
+			return Image{}, err
+		}
+		out.ContentType = "image/png"
+		out.Ext = ".png"
+	}
+
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+// Thumbnail scales img down so that its longest edge is at most size,
+// keeping the aspect ratio. Each destination pixel is the average of the
+// source pixels it covers. Images that already fit are copied unchanged.
+func Thumbnail(img image.Image, size int) *image.RGBA {
+	src := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW = size
+			dstH = max(1, srcH*size/srcW)
+		} else {
+			dstH = size
+			dstW = max(1, srcW*size/srcH)
+		}
+	}
+	if dstW == srcW && dstH == srcH {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := y * srcH / dstH
+		y1 := max(y0+1, (y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := x * srcW / dstW
+			x1 := max(x0+1, (x+1)*srcW/dstW)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := src.PixOffset(sx, sy)
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
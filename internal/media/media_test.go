@@ -0,0 +1,101 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func TestProcessStripsExif(t *testing.T) {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, testImage(64, 48), nil)
+	if err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+
+	// Splice an APP1 EXIF segment in right after the SOI marker.
+	exif := []byte("Exif\x00\x00GPS-SECRET")
+	segment := append([]byte{0xFF, 0xE1, 0x00, byte(len(exif) + 2)}, exif...)
+	data := append([]byte{}, buf.Bytes()[:2]...)
+	data = append(data, segment...)
+	data = append(data, buf.Bytes()[2:]...)
+
+	processed, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process: expected no error, got %v", err)
+	}
+	if bytes.Contains(processed.Original.Data, []byte("GPS-SECRET")) {
+		t.Fatalf("Process: expected EXIF to be stripped from the original")
+	}
+	if processed.Original.ContentType != "image/jpeg" || processed.Original.Ext != ".jpg" {
+		t.Fatalf("Process: expected image/jpeg, got %s (%s)", processed.Original.ContentType, processed.Original.Ext)
+	}
+	if processed.Original.Width != 64 || processed.Original.Height != 48 {
+		t.Fatalf("Process: expected 64x48, got %dx%d", processed.Original.Width, processed.Original.Height)
+	}
+}
+
+func TestProcessThumbnail(t *testing.T) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, testImage(800, 400))
+	if err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+
+	processed, err := Process(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Process: expected no error, got %v", err)
+	}
+
+	thumb := processed.Thumbnail
+	if thumb.Width != ThumbnailSize || thumb.Height != ThumbnailSize/2 {
+		t.Fatalf("Process: expected %dx%d thumbnail, got %dx%d", ThumbnailSize, ThumbnailSize/2, thumb.Width, thumb.Height)
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(thumb.Data))
+	if err != nil {
+		t.Fatalf("Process: thumbnail is not a valid PNG: %v", err)
+	}
+	if decoded.Bounds().Dx() != thumb.Width || decoded.Bounds().Dy() != thumb.Height {
+		t.Fatalf("Process: thumbnail data does not match reported dimensions")
+	}
+}
+
+func TestThumbnailSmallImage(t *testing.T) {
+	thumb := Thumbnail(testImage(10, 20), ThumbnailSize)
+	if thumb.Bounds().Dx() != 10 || thumb.Bounds().Dy() != 20 {
+		t.Fatalf("Thumbnail: expected 10x20, got %dx%d", thumb.Bounds().Dx(), thumb.Bounds().Dy())
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "text", data: []byte("hello, not an image"), err: ErrUnsupportedType},
+		{name: "too large", data: make([]byte, MaxImageBytes+1), err: ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		_, err := Process(tt.data)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("Process(%s): expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Package moderation checks chirp text against a configurable list of word
+// rules. Each rule says whether a matching word is masked, holds the chirp
+// for review or rejects it outright.
+package moderation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Action string
+
+const (
+	ActionMask   Action = "mask"
+	ActionHold   Action = "hold"
+	ActionReject Action = "reject"
+)
+
+// mask replaces the letters of a masked word. Punctuation around the word,
+// including a leading #, is kept.
+const mask = "****"
+
+// ParseAction validates an action name.
+func ParseAction(name string) (Action, error) {
+	switch action := Action(name); action {
+	case ActionMask, ActionHold, ActionReject:
+		return action, nil
+	}
+	return "", fmt.Errorf("unknown moderation action %q", name)
+}
+
+// severity orders actions so the strongest match decides a verdict.
+func (a Action) severity() int {
+	switch a {
+	case ActionMask:
+		return 1
+	case ActionHold:
+		return 2
+	case ActionReject:
+		return 3
+	}
+	return 0
+}
+
+type Rule struct {
+	Term   string
+	Action Action
+}
+
+// Verdict is the outcome of checking a text.
+type Verdict struct {
+	// Text is the input with words matching mask rules masked. Whitespace
+	// and everything else is left exactly as it was.
+	Text string
+	// Action is the strongest action among the matches, or empty when
+	// nothing matched.
+	Action Action
+	// Matches lists the rules that matched, once per matching word.
+	Matches []Rule
+}
+
+// Filter is an immutable, compiled set of rules.
+type Filter struct {
+	terms map[string]Action
+}
+
+// NewFilter compiles rules into a Filter. Terms are normalised the same way
+// as the words they are compared against, and a term listed twice keeps its
+// strongest action.
+func NewFilter(rules []Rule) (*Filter, error) {
+	f := &Filter{terms: make(map[string]Action, len(rules))}
+	for _, rule := range rules {
+		action, err := ParseAction(string(rule.Action))
+		if err != nil {
+			return nil, err
+		}
+		term, _, _ := normalize(rule.Term)
+		if term == "" {
+			return nil, fmt.Errorf("moderation term %q has no letters or digits", rule.Term)
+		}
+		if strings.ContainsFunc(strings.TrimSpace(rule.Term), unicode.IsSpace) {
+			return nil, fmt.Errorf("moderation term %q must be a single word", rule.Term)
+		}
+		if existing, ok := f.terms[term]; !ok || action.severity() > existing.severity() {
+			f.terms[term] = action
+		}
+	}
+	return f, nil
+}
+
+// Check runs text through the filter.
+func (f *Filter) Check(text string) Verdict {
+	verdict := Verdict{}
+	var out strings.Builder
+	out.Grow(len(text))
+
+	wordStart := -1
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			if wordStart >= 0 {
+				out.WriteString(f.checkWord(text[wordStart:i], &verdict))
+				wordStart = -1
+			}
+			out.WriteString(text[i : i+size])
+		} else if wordStart < 0 {
+			wordStart = i
+		}
+		i += size
+	}
+	if wordStart >= 0 {
+		out.WriteString(f.checkWord(text[wordStart:], &verdict))
+	}
+
+	verdict.Text = out.String()
+	return verdict
+}
+
+func (f *Filter) checkWord(word string, verdict *Verdict) string {
+	term, first, last := normalize(word)
+	action, ok := f.terms[term]
+	if !ok {
+		return word
+	}
+
+	verdict.Matches = append(verdict.Matches, Rule{Term: term, Action: action})
+	if action.severity() > verdict.Action.severity() {
+		verdict.Action = action
+	}
+	if action != ActionMask {
+		return word
+	}
+	return word[:first] + mask + word[last:]
+}
+
+// confusables maps characters commonly used to dodge word filters onto the
+// ASCII letter they imitate. Lookups happen after lower-casing.
+var confusables = map[rune]rune{
+	// Digits and symbols.
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's',
+	// Cyrillic.
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's',
+	// Greek.
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+	// Accented Latin.
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'ç': 'c', 'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i', 'ñ': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u', 'ý': 'y', 'ÿ': 'y',
+}
+
+// normalize folds a word to the form rules are compared in: lower case,
+// confusables and full-width forms mapped to ASCII, and everything but
+// letters and digits dropped, so "K.e.r.f" and "kerf" compare equal.
+// first and last are the byte offsets of the part of word that produced
+// the term, which is what gets masked.
+func normalize(word string) (term string, first, last int) {
+	var b strings.Builder
+	first, last = -1, -1
+	for i, original := range word {
+		r := original
+		// Full-width forms sit at a fixed offset from ASCII.
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+		r = unicode.ToLower(r)
+		if mapped, ok := confusables[r]; ok {
+			r = mapped
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		b.WriteRune(r)
+		if first < 0 {
+			first = i
+		}
+		last = i + utf8.RuneLen(original)
+	}
+	if first < 0 {
+		return "", 0, 0
+	}
+	return b.String(), first, last
+}
+
+// ParseRules reads rules in the word list format: one term per line,
+// optionally followed by an action, which defaults to mask. Blank lines and
+// lines starting with # are ignored.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected a term and an optional action", lineNo)
+		}
+		rule := Rule{Term: fields[0], Action: ActionMask}
+		if len(fields) == 2 {
+			action, err := ParseAction(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			rule.Action = action
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// LoadFile reads a word list from path.
+func LoadFile(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseRules(f)
+}
+
+// Moderator holds the active Filter. The filter can be replaced at any time;
+// checks already running finish against the old one.
+type Moderator struct {
+	filter atomic.Pointer[Filter]
+}
+
+// NewModerator returns a Moderator with no rules, which lets everything
+// through until SetFilter is called.
+func NewModerator() *Moderator {
+	m := &Moderator{}
+	m.filter.Store(&Filter{terms: map[string]Action{}})
+	return m
+}
+
+func (m *Moderator) Check(text string) Verdict {
+	return m.filter.Load().Check(text)
+}
+
+func (m *Moderator) SetFilter(filter *Filter) {
+	m.filter.Store(filter)
+}
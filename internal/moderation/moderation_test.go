@@ -0,0 +1,164 @@
+package moderation
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestFilter(t *testing.T) *Filter {
+	t.Helper()
+	filter, err := NewFilter([]Rule{
+		{Term: "kerfuffle", Action: ActionMask},
+		{Term: "sharbert", Action: ActionMask},
+		{Term: "fornax", Action: ActionHold},
+		{Term: "zorblax", Action: ActionReject},
+	})
+	if err != nil {
+		t.Fatalf("NewFilter: expected no error, got %v", err)
+	}
+	return filter
+}
+
+func TestCheckMasks(t *testing.T) {
+	filter := newTestFilter(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "hello world", expected: "hello world"},
+		{input: "what a kerfuffle", expected: "what a ****"},
+		{input: "Kerfuffle!", expected: "****!"},
+		{input: "(kerfuffle),", expected: "(****),"},
+		{input: "#kerfuffle", expected: "#****"},
+		{input: "a  kerfuffle\nsharbert\tend", expected: "a  ****\n****\tend"},
+		{input: "k.e.r.f.u.f.f.l.e", expected: "****"},
+		// Zero-width space.
+		{input: "ker\u200bfuffle", expected: "****"},
+		// Cyrillic е and а.
+		{input: "kеrfufflе shаrbert", expected: "**** ****"},
+		// Full-width letters.
+		{input: "ｋｅｒｆｕｆｆｌｅ", expected: "****"},
+		{input: "k3rfuffl3 $harbert", expected: "**** ****"},
+		{input: "kérfuffle", expected: "****"},
+		{input: "kerfuffles", expected: "kerfuffles"},
+		{input: "", expected: ""},
+	}
+
+	for _, test := range tests {
+		verdict := filter.Check(test.input)
+		if verdict.Text != test.expected {
+			t.Fatalf("Check(%q): expected text %q, got %q", test.input, test.expected, verdict.Text)
+		}
+	}
+}
+
+func TestCheckActions(t *testing.T) {
+	filter := newTestFilter(t)
+
+	tests := []struct {
+		input    string
+		action   Action
+		expected string
+		matches  int
+	}{
+		{input: "all clear", action: "", expected: "all clear", matches: 0},
+		{input: "kerfuffle", action: ActionMask, expected: "****", matches: 1},
+		{input: "fornax kerfuffle", action: ActionHold, expected: "fornax ****", matches: 2},
+		{input: "Fornax zorblax!", action: ActionReject, expected: "Fornax zorblax!", matches: 2},
+	}
+
+	for _, test := range tests {
+		verdict := filter.Check(test.input)
+		if verdict.Action != test.action {
+			t.Fatalf("Check(%q): expected action %q, got %q", test.input, test.action, verdict.Action)
+		}
+		if verdict.Text != test.expected {
+			t.Fatalf("Check(%q): expected text %q, got %q", test.input, test.expected, verdict.Text)
+		}
+		if len(verdict.Matches) != test.matches {
+			t.Fatalf("Check(%q): expected %d matches, got %d", test.input, test.matches, len(verdict.Matches))
+		}
+	}
+}
+
+func TestNewFilterStrongestActionWins(t *testing.T) {
+	filter, err := NewFilter([]Rule{
+		{Term: "Kerfuffle", Action: ActionReject},
+		{Term: "kerfuffle", Action: ActionMask},
+	})
+	if err != nil {
+		t.Fatalf("NewFilter: expected no error, got %v", err)
+	}
+
+	verdict := filter.Check("kerfuffle")
+	if verdict.Action != ActionReject {
+		t.Fatalf("Check: expected action %q, got %q", ActionReject, verdict.Action)
+	}
+}
+
+func TestNewFilterInvalid(t *testing.T) {
+	tests := []Rule{
+		{Term: "kerfuffle", Action: "delete"},
+		{Term: "...", Action: ActionMask},
+		{Term: "two words", Action: ActionMask},
+	}
+
+	for _, rule := range tests {
+		_, err := NewFilter([]Rule{rule})
+		if err == nil {
+			t.Fatalf("NewFilter(%+v): expected error, got no error", rule)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	input := `
+# Words to mask by default.
+kerfuffle
+sharbert   mask
+
+fornax hold
+zorblax reject
+`
+	rules, err := ParseRules(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseRules: expected no error, got %v", err)
+	}
+
+	expected := []Rule{
+		{Term: "kerfuffle", Action: ActionMask},
+		{Term: "sharbert", Action: ActionMask},
+		{Term: "fornax", Action: ActionHold},
+		{Term: "zorblax", Action: ActionReject},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("ParseRules: expected %d rules, got %d", len(expected), len(rules))
+	}
+	for i := range expected {
+		if rules[i] != expected[i] {
+			t.Fatalf("ParseRules: rule %d: expected %+v, got %+v", i, expected[i], rules[i])
+		}
+	}
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	for _, input := range []string{"kerfuffle delete", "kerfuffle mask extra"} {
+		_, err := ParseRules(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("ParseRules(%q): expected error, got no error", input)
+		}
+	}
+}
+
+func TestModeratorSetFilter(t *testing.T) {
+	moderator := NewModerator()
+	if got := moderator.Check("kerfuffle").Text; got != "kerfuffle" {
+		t.Fatalf("Check: expected %q, got %q", "kerfuffle", got)
+	}
+
+	moderator.SetFilter(newTestFilter(t))
+	if got := moderator.Check("kerfuffle").Text; got != "****" {
+		t.Fatalf("Check after SetFilter: expected %q, got %q", "****", got)
+	}
+}
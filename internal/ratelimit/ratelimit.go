@@ -0,0 +1,69 @@
+// Package ratelimit counts events per key, such as a client IP or an email
+// address, and refuses them past a fixed limit per time window.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// Limiter allows up to limit events per key in each fixed window. State is
+// kept in memory, so every server instance enforces its own limit.
+type Limiter struct {
+	mu        sync.Mutex
+	limit     int
+	period    time.Duration
+	windows   map[string]*window
+	nextSweep time.Time
+
+	now func() time.Time
+}
+
+func New(limit int, period time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		period:  period,
+		windows: map[string]*window{},
+		now:     time.Now,
+	}
+}
+
+// Allow records an event for key and reports whether it is within the
+// limit. Refused events are not counted.
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.period {
+		l.windows[key] = &window{start: now, count: 1}
+		return true
+	}
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// sweep drops expired windows once per period, so keys seen only once do
+// not pile up.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.period {
+			delete(l.windows, key)
+		}
+	}
+	l.nextSweep = now.Add(l.period)
+}
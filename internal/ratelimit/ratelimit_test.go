@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLimiter(limit int, period time.Duration) (*Limiter, *time.Time) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	l := New(limit, period)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestAllow(t *testing.T) {
+	l, now := newTestLimiter(3, time.Minute)
+
+	for i := range 3 {
+		if !l.Allow("a") {
+			t.Fatalf("Allow(%q) #%d: expected true, got false", "a", i+1)
+		}
+	}
+	if l.Allow("a") {
+		t.Fatalf("Allow(%q) #4: expected false, got true", "a")
+	}
+	// Keys are counted separately.
+	if !l.Allow("b") {
+		t.Fatalf("Allow(%q): expected true, got false", "b")
+	}
+
+	*now = now.Add(59 * time.Second)
+	if l.Allow("a") {
+		t.Fatalf("Allow(%q) before the window ends: expected false, got true", "a")
+	}
+
+	*now = now.Add(time.Second)
+	if !l.Allow("a") {
+		t.Fatalf("Allow(%q) in a new window: expected true, got false", "a")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	l, now := newTestLimiter(1, time.Minute)
+
+	l.Allow("a")
+	l.Allow("b")
+	*now = now.Add(time.Minute)
+	l.Allow("c")
+
+	if len(l.windows) != 1 {
+		t.Fatalf("expected expired windows to be dropped, got %d windows", len(l.windows))
+	}
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ParseQuery turns user input into a to_tsquery expression.
+//
+// Bare words are ANDed together, "quoted text" becomes a phrase query,
+// a trailing * makes a prefix match, a leading - negates a term and the
+// keyword OR joins its neighbours with | instead of &.
+func ParseQuery(input string) (string, error) {
+	var terms []string
+	pendingOr := false
+
+	for _, token := range tokenize(input) {
+		if !token.quoted && token.text == "OR" {
+			if len(terms) > 0 {
+				pendingOr = true
+			}
+			continue
+		}
+
+		term := buildTerm(token)
+		if term == "" {
+			continue
+		}
+
+		if len(terms) > 0 {
+			if pendingOr {
+				terms = append(terms, "|")
+			} else {
+				terms = append(terms, "&")
+			}
+		}
+		terms = append(terms, term)
+		pendingOr = false
+	}
+
+	if len(terms) == 0 {
+		return "", fmt.Errorf("query contains no searchable terms")
+	}
+
+	return strings.Join(terms, " "), nil
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(input string) []token {
+	var tokens []token
+	var current strings.Builder
+	quoted := false
+
+	flush := func() {
+		if current.Len() > 0 || quoted {
+			tokens = append(tokens, token{text: current.String(), quoted: quoted})
+		}
+		current.Reset()
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+func buildTerm(t token) string {
+	text := t.text
+	negated := false
+	prefix := false
+
+	if !t.quoted {
+		if strings.HasPrefix(text, "-") {
+			negated = true
+			text = strings.TrimLeft(text, "-")
+		}
+		if strings.HasSuffix(text, "*") {
+			prefix = true
+			text = strings.TrimRight(text, "*")
+		}
+	}
+
+	// Anything that is not a letter or a digit would be tsquery syntax, so
+	// it only ever separates words.
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	if prefix {
+		words[len(words)-1] += ":*"
+	}
+
+	term := words[0]
+	if len(words) > 1 {
+		term = "(" + strings.Join(words, " <-> ") + ")"
+	}
+	if negated {
+		term = "!" + term
+	}
+
+	return term
+}
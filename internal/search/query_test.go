@@ -0,0 +1,40 @@
+package search
+
+import "testing"
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "hello", expected: "hello"},
+		{input: "Hello World", expected: "hello & world"},
+		{input: `"hello world"`, expected: "(hello <-> world)"},
+		{input: "chirp*", expected: "chirp:*"},
+		{input: "cats OR dogs", expected: "cats | dogs"},
+		{input: "cats -dogs", expected: "cats & !dogs"},
+		{input: `"big cat" kit*`, expected: "(big <-> cat) & kit:*"},
+		{input: "don't", expected: "(don <-> t)"},
+		{input: "a & b | !c", expected: "a & b & c"},
+		{input: "OR cats", expected: "cats"},
+	}
+
+	for _, test := range tests {
+		got, err := ParseQuery(test.input)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): expected no error, got %v", test.input, err)
+		}
+		if got != test.expected {
+			t.Fatalf("ParseQuery(%q): expected %q, got %q", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestParseQueryEmpty(t *testing.T) {
+	for _, input := range []string{"", "   ", `""`, "&|!", "OR"} {
+		_, err := ParseQuery(input)
+		if err == nil {
+			t.Fatalf("ParseQuery(%q): expected error, got no error", input)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrNotFound is returned by Open when no object exists under the name.
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps immutable blobs under flat names such as "<sha256>.png".
+type Storage interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+	Open(ctx context.Context, name string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9]+)?$`)
+
+func validName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid object name %q", name)
+	}
+	return nil
+}
+
+// LocalDisk stores objects as files inside a single directory.
+type LocalDisk struct {
+	root string
+}
+
+func NewLocalDisk(root string) (*LocalDisk, error) {
+	err := os.MkdirAll(root, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalDisk{root: root}, nil
+}
+
+// Put writes the object through a temporary file so readers never see a
+// partial object. Names are content-addressed, so an existing object is
+// left untouched.
+func (d *LocalDisk) Put(ctx context.Context, name string, r io.Reader) error {
+	err := validName(name)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(d.root, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(d.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d *LocalDisk) Open(ctx context.Context, name string) (io.ReadSeekCloser, error) {
+	err := validName(name)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(filepath.Join(d.root, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete removes the object. Deleting a missing object is not an error.
+func (d *LocalDisk) Delete(ctx context.Context, name string) error {
+	err := validName(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(d.root, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
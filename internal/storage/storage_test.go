@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalDiskPutOpen(t *testing.T) {
+	disk, err := NewLocalDisk(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalDisk: expected no error, got %v", err)
+	}
+	ctx := context.Background()
+
+	err = disk.Put(ctx, "abc123.png", strings.NewReader("first"))
+	if err != nil {
+		t.Fatalf(`Put("abc123.png"): expected no error, got %v`, err)
+	}
+
+	// Objects are immutable, a second Put under the same name is a no-op.
+	err = disk.Put(ctx, "abc123.png", strings.NewReader("second"))
+	if err != nil {
+		t.Fatalf(`Put("abc123.png") again: expected no error, got %v`, err)
+	}
+
+	f, err := disk.Open(ctx, "abc123.png")
+	if err != nil {
+		t.Fatalf(`Open("abc123.png"): expected no error, got %v`, err)
+	}
+	defer f.Close()
+
+	dat, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf(`Open("abc123.png"): error reading object: %v`, err)
+	}
+	if !bytes.Equal(dat, []byte("first")) {
+		t.Fatalf(`Open("abc123.png"): expected "first", got %q`, dat)
+	}
+}
+
+func TestLocalDiskRejectsBadNames(t *testing.T) {
+	disk, err := NewLocalDisk(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalDisk: expected no error, got %v", err)
+	}
+	ctx := context.Background()
+
+	for _, name := range []string{"", "../secret", "a/b.png", ".hidden", "a.b.c"} {
+		err = disk.Put(ctx, name, strings.NewReader("x"))
+		if err == nil {
+			t.Fatalf("Put(%q): expected error, got no error", name)
+		}
+
+		_, err = disk.Open(ctx, name)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Open(%q): expected ErrNotFound, got %v", name, err)
+		}
+	}
+}
+
+func TestLocalDiskOpenMissing(t *testing.T) {
+	disk, err := NewLocalDisk(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalDisk: expected no error, got %v", err)
+	}
+
+	_, err = disk.Open(context.Background(), "missing.png")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf(`Open("missing.png"): expected ErrNotFound, got %v`, err)
+	}
+}
+
+func TestLocalDiskDelete(t *testing.T) {
+	disk, err := NewLocalDisk(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalDisk: expected no error, got %v", err)
+	}
+	ctx := context.Background()
+
+	err = disk.Put(ctx, "abc123.png", strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf(`Put("abc123.png"): expected no error, got %v`, err)
+	}
+
+	err = disk.Delete(ctx, "abc123.png")
+	if err != nil {
+		t.Fatalf(`Delete("abc123.png"): expected no error, got %v`, err)
+	}
+	_, err = disk.Open(ctx, "abc123.png")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf(`Open("abc123.png") after Delete: expected ErrNotFound, got %v`, err)
+	}
+
+	// Deleting is idempotent.
+	err = disk.Delete(ctx, "abc123.png")
+	if err != nil {
+		t.Fatalf(`Delete("abc123.png") again: expected no error, got %v`, err)
+	}
+
+	err = disk.Delete(ctx, "../secret")
+	if err == nil {
+		t.Fatalf(`Delete("../secret"): expected error, got no error`)
+	}
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 
 	"github.com/joho/godotenv"
 	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/jradziejewski/chirpy/internal/mailer"
+	"github.com/jradziejewski/chirpy/internal/storage"
 	_ "github.com/lib/pq"
 )
 
@@ -31,9 +35,41 @@ func main() {
 
 	dbQueries := database.New(db)
 
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "media"
+	}
+	mediaStorage, err := storage.NewLocalDisk(mediaDir)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	// Outgoing mail is written to MAIL_LOG_FILE, or stdout, rather than
+	// delivered.
+	var mailLog io.Writer = os.Stdout
+	if path := os.Getenv("MAIL_LOG_FILE"); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			fmt.Println("Error opening mail log:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		mailLog = f
+	}
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "chirpy@localhost"
+	}
+	mail := mailer.NewLogMailer(mailLog, mailFrom)
+
 	mux := http.NewServeMux()
 	fileServer := http.FileServer(http.Dir("."))
-	apiCfg := newApiConfig(dbQueries)
+	apiCfg := newApiConfig(db, dbQueries, mediaStorage, mail)
+	err = apiCfg.loadModerationRules(context.Background())
+	if err != nil {
+		fmt.Println("Error loading moderation rules:", err)
+		os.Exit(1)
+	}
 	mux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", fileServer)))
 
 	mux.HandleFunc("GET /api/healthz", handlerHealth)
@@ -41,15 +77,74 @@ func main() {
 	// Users
 	mux.HandleFunc("POST /api/users", apiCfg.handlerCreateUser)
 	mux.HandleFunc("POST /api/login", apiCfg.handlerLogin)
+	mux.HandleFunc("POST /api/login/2fa", apiCfg.handlerLoginTwoFactor)
 	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefresh)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevoke)
+	mux.HandleFunc("POST /api/users/verify", apiCfg.handlerVerifyEmail)
+	mux.HandleFunc("POST /api/users/verify/resend", apiCfg.handlerResendVerification)
+	mux.HandleFunc("POST /api/password/forgot", apiCfg.handlerForgotPassword)
+	mux.HandleFunc("POST /api/password/reset", apiCfg.handlerResetPassword)
 	mux.HandleFunc("PUT /api/users", apiCfg.handlerCredentialsChange)
+	mux.HandleFunc("GET /api/users/me/mentions", apiCfg.handlerGetMyMentions)
+	mux.HandleFunc("GET /api/users/me/bookmarks", apiCfg.handlerGetMyBookmarks)
+	mux.HandleFunc("GET /api/users/me/blocks", apiCfg.handlerGetMyBlocks)
+	mux.HandleFunc("GET /api/users/me/mutes", apiCfg.handlerGetMyMutes)
+	mux.HandleFunc("GET /api/users/me/export", apiCfg.handlerExportAccount)
+	mux.HandleFunc("DELETE /api/users/me", apiCfg.handlerDeleteAccount)
+	mux.HandleFunc("POST /api/users/me/2fa/enroll", apiCfg.handlerEnrollTwoFactor)
+	mux.HandleFunc("POST /api/users/me/2fa/confirm", apiCfg.handlerConfirmTwoFactor)
+	mux.HandleFunc("DELETE /api/users/me/2fa", apiCfg.handlerDisableTwoFactor)
+	mux.HandleFunc("PUT /api/users/me/pinned_chirp", apiCfg.handlerPinChirp)
+	mux.HandleFunc("GET /api/users/{username}", apiCfg.handlerGetUserProfile)
+
+	// Follows
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.handlerFollowUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.handlerUnfollowUser)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handlerGetFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handlerGetFollowing)
+	mux.HandleFunc("POST /api/users/{userID}/block", apiCfg.handlerBlockUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/block", apiCfg.handlerUnblockUser)
+	mux.HandleFunc("POST /api/users/{userID}/mute", apiCfg.handlerMuteUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/mute", apiCfg.handlerUnmuteUser)
+	mux.HandleFunc("GET /api/timeline", apiCfg.handlerGetTimeline)
 
 	// Chirps
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.handlerSearchChirps)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handlerGetChirp)
 	mux.HandleFunc("GET /api/chirps", apiCfg.handlerGetChirps)
 	mux.HandleFunc("POST /api/chirps", apiCfg.handlerCreateChirp)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.handlerUpdateChirp)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerDeleteChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/restore", apiCfg.handlerRestoreChirp)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/history", apiCfg.handlerGetChirpHistory)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handlerGetChirpThread)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/like", apiCfg.handlerLikeChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", apiCfg.handlerUnlikeChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.handlerRechirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.handlerUndoRechirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", apiCfg.handlerVotePoll)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/bookmark", apiCfg.handlerBookmarkChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", apiCfg.handlerRemoveBookmark)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/report", apiCfg.handlerReportChirp)
+
+	// Scheduled chirps
+	mux.HandleFunc("GET /api/chirps/scheduled", apiCfg.handlerGetScheduledChirps)
+	mux.HandleFunc("POST /api/chirps/scheduled/{scheduledID}/cancel", apiCfg.handlerCancelScheduledChirp)
+
+	// Drafts
+	mux.HandleFunc("POST /api/drafts", apiCfg.handlerCreateDraft)
+	mux.HandleFunc("GET /api/drafts", apiCfg.handlerGetDrafts)
+	mux.HandleFunc("GET /api/drafts/{draftID}", apiCfg.handlerGetDraft)
+	mux.HandleFunc("PUT /api/drafts/{draftID}", apiCfg.handlerUpdateDraft)
+	mux.HandleFunc("DELETE /api/drafts/{draftID}", apiCfg.handlerDeleteDraft)
+	mux.HandleFunc("POST /api/drafts/{draftID}/publish", apiCfg.handlerPublishDraft)
+
+	// Hashtags
+	mux.HandleFunc("GET /api/hashtags/trending", apiCfg.handlerGetTrendingHashtags)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.handlerGetHashtagChirps)
+
+	// Media
+	mux.HandleFunc("GET /media/{name}", apiCfg.handlerGetMedia)
 
 	// Webhooks
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.HandlerUpdateIsChirpyRed)
@@ -57,11 +152,24 @@ func main() {
 	// Admin
 	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerHits)
 	mux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
+	mux.HandleFunc("GET /admin/moderation/rules", apiCfg.handlerGetModerationRules)
+	mux.HandleFunc("POST /admin/moderation/rules", apiCfg.handlerSaveModerationRule)
+	mux.HandleFunc("DELETE /admin/moderation/rules/{ruleID}", apiCfg.handlerDeleteModerationRule)
+	mux.HandleFunc("GET /admin/moderation/queue", apiCfg.handlerGetModerationQueue)
+	mux.HandleFunc("POST /admin/moderation/queue/{chirpID}", apiCfg.handlerModerateChirp)
+	mux.HandleFunc("GET /admin/moderation/actions", apiCfg.handlerGetModerationActions)
 	server := &http.Server{
 		Handler: mux,
 		Addr:    ":8080",
 	}
 
+	go apiCfg.runPublisher(context.Background())
+	go apiCfg.runPurger(context.Background())
+	go apiCfg.runModerationReloader(context.Background())
+	for range passwordResetWorkers {
+		go apiCfg.runPasswordResetWorker(context.Background())
+	}
+
 	fmt.Println("Chirpy server started!")
 	err = server.ListenAndServe()
 	if err != nil {
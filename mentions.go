@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])(@([A-Za-z0-9_]+))`)
+
+// mention is an @handle found in a chirp body. Offset and Length count
+// Unicode code points and cover the leading @.
+type mention struct {
+	Username string
+	Offset   int
+	Length   int
+}
+
+func extractMentions(body string) []mention {
+	var mentions []mention
+
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(body, -1) {
+		start, end := match[2], match[3]
+		username := body[match[4]:match[5]]
+		if validateUsername(username) != nil {
+			continue
+		}
+		mentions = append(mentions, mention{
+			Username: strings.ToLower(username),
+			Offset:   utf8.RuneCountInString(body[:start]),
+			Length:   utf8.RuneCountInString(body[start:end]),
+		})
+	}
+
+	return mentions
+}
+
+// indexMentions replaces the mentions stored for chirp with the ones in its
+// current body. Handles that do not belong to any user are ignored.
+func indexMentions(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	err := q.DeleteChirpMentions(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	mentions := extractMentions(chirp.Body)
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	usernames := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		usernames = append(usernames, m.Username)
+	}
+	users, err := q.GetUsersByUsernames(ctx, usernames)
+	if err != nil {
+		return err
+	}
+	usersByName := make(map[string]database.User, len(users))
+	for _, user := range users {
+		usersByName[strings.ToLower(user.Username.String)] = user
+	}
+
+	now := time.Now().UTC()
+	for _, m := range mentions {
+		user, ok := usersByName[m.Username]
+		if !ok {
+			continue
+		}
+		err = q.CreateChirpMention(ctx, database.CreateChirpMentionParams{
+			ChirpID:     chirp.ID,
+			UserID:      user.ID,
+			StartOffset: int32(m.Offset),
+			Length:      int32(m.Length),
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
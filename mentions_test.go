@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []mention
+	}{
+		{input: "hi @alice", expected: []mention{{Username: "alice", Offset: 3, Length: 6}}},
+		{input: "@Bob_1 first", expected: []mention{{Username: "bob_1", Offset: 0, Length: 6}}},
+		{input: "(@carol), @dave!", expected: []mention{
+			{Username: "carol", Offset: 1, Length: 6},
+			{Username: "dave", Offset: 10, Length: 5},
+		}},
+		{input: "héllo\n@erin", expected: []mention{{Username: "erin", Offset: 6, Length: 5}}},
+		{input: "mail me at frank@example.com", expected: nil},
+		{input: "@@grace", expected: nil},
+		{input: "@ab is too short", expected: nil},
+		{input: "@1abc starts with a digit", expected: nil},
+		{input: "@abcdefghijklmnop is too long", expected: nil},
+		{input: "@admin is reserved", expected: nil},
+		{input: "no mentions here", expected: nil},
+	}
+
+	for _, test := range tests {
+		got := extractMentions(test.input)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Fatalf("extractMentions(%q): expected %v, got %v", test.input, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jradziejewski/chirpy/internal/moderation"
+)
+
+const (
+	chirpStatusPublished = "published"
+	chirpStatusHeld      = "held"
+	chirpStatusHidden    = "hidden"
+
+	moderationReloadInterval = time.Minute
+)
+
+const (
+	reportStatusResolved  = "resolved"
+	reportStatusDismissed = "dismissed"
+
+	maxReportDetailsLength = 500
+)
+
+// reportReasons are the reason codes a chirp can be reported for.
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate":           true,
+	"violence":       true,
+	"sexual":         true,
+	"misinformation": true,
+	"other":          true,
+}
+
+// Actions a moderator can take on a chirp in the review queue.
+const (
+	moderationActionResolve = "resolve"
+	moderationActionDismiss = "dismiss"
+	moderationActionHide    = "hide"
+	moderationActionSuspend = "suspend"
+)
+
+var errChirpRejected = errors.New("Chirp breaks the content rules")
+
+// cleanChirpBody applies the validation and moderation every stored chirp
+// body goes through. It returns the body to store and the status a chirp
+// with that body is published with.
+func (cfg *apiConfig) cleanChirpBody(body string) (string, string, error) {
+	if len(body) > 140 {
+		return "", "", fmt.Errorf("Body too long")
+	} else if len(body) == 0 {
+		return "", "", fmt.Errorf("Empty body")
+	}
+
+	verdict := cfg.moderator.Check(body)
+	if verdict.Action == moderation.ActionReject {
+		return "", "", errChirpRejected
+	}
+	// Masking a term shorter than the mask makes the body longer. The limit
+	// applies to what is stored, so a body that is cleaned again later, when
+	// a draft or scheduled chirp is published, still passes.
+	if len(verdict.Text) > 140 {
+		return "", "", fmt.Errorf("Body too long")
+	}
+	if verdict.Action == moderation.ActionHold {
+		return verdict.Text, chirpStatusHeld, nil
+	}
+	return verdict.Text, chirpStatusPublished, nil
+}
+
+// cleanPollOptions applies the moderation of chirp bodies to poll options.
+// It returns the options to store and the chirp's status, which becomes
+// held if any option is held. status is the status from cleanChirpBody.
+func (cfg *apiConfig) cleanPollOptions(options []string, status string) ([]string, string, error) {
+	cleaned := make([]string, 0, len(options))
+	for _, option := range options {
+		verdict := cfg.moderator.Check(option)
+		if verdict.Action == moderation.ActionReject {
+			return nil, "", errChirpRejected
+		}
+		if len([]rune(verdict.Text)) > maxPollOptionLength {
+			return nil, "", fmt.Errorf("Poll options cannot be longer than %d characters", maxPollOptionLength)
+		}
+		if verdict.Action == moderation.ActionHold {
+			status = chirpStatusHeld
+		}
+		cleaned = append(cleaned, verdict.Text)
+	}
+	return cleaned, status, nil
+}
+
+// loadModerationRules rebuilds the moderation filter from the rules file, if
+// one is configured, and the moderation_rules table. A term listed in both
+// keeps its strongest action.
+func (cfg *apiConfig) loadModerationRules(ctx context.Context) error {
+	var rules []moderation.Rule
+	if cfg.moderationRulesFile != "" {
+		fileRules, err := moderation.LoadFile(cfg.moderationRulesFile)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	dbRules, err := cfg.db.GetModerationRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rule := range dbRules {
+		rules = append(rules, moderation.Rule{
+			Term:   rule.Term,
+			Action: moderation.Action(rule.Action),
+		})
+	}
+
+	filter, err := moderation.NewFilter(rules)
+	if err != nil {
+		return err
+	}
+	cfg.moderator.SetFilter(filter)
+	return nil
+}
+
+// runModerationReloader reloads the moderation rules until ctx is cancelled,
+// so rules edited through another server instance take effect here too.
+func (cfg *apiConfig) runModerationReloader(ctx context.Context) {
+	ticker := time.NewTicker(moderationReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := cfg.loadModerationRules(ctx)
+		if err != nil {
+			log.Printf("Error reloading moderation rules: %s", err)
+		}
+	}
+}
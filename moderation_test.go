@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jradziejewski/chirpy/internal/moderation"
+)
+
+func newTestModerationConfig(t *testing.T) *apiConfig {
+	filter, err := moderation.NewFilter([]moderation.Rule{
+		{Term: "kerfuffle", Action: moderation.ActionMask},
+		{Term: "fornax", Action: moderation.ActionHold},
+		{Term: "blorp", Action: moderation.ActionReject},
+		{Term: "zap", Action: moderation.ActionMask},
+	})
+	if err != nil {
+		t.Fatalf("NewFilter: expected no error, got %v", err)
+	}
+	cfg := &apiConfig{moderator: moderation.NewModerator()}
+	cfg.moderator.SetFilter(filter)
+	return cfg
+}
+
+func TestCleanPollOptions(t *testing.T) {
+	cfg := newTestModerationConfig(t)
+
+	tests := []struct {
+		options  []string
+		status   string
+		expected []string
+		want     string
+	}{
+		{options: []string{"yes", "no"}, status: chirpStatusPublished, expected: []string{"yes", "no"}, want: chirpStatusPublished},
+		{options: []string{"a kerfuffle", "no"}, status: chirpStatusPublished, expected: []string{"a ****", "no"}, want: chirpStatusPublished},
+		{options: []string{"fornax", "no"}, status: chirpStatusPublished, expected: []string{"fornax", "no"}, want: chirpStatusHeld},
+		{options: []string{"yes", "no"}, status: chirpStatusHeld, expected: []string{"yes", "no"}, want: chirpStatusHeld},
+	}
+
+	for _, test := range tests {
+		got, status, err := cfg.cleanPollOptions(test.options, test.status)
+		if err != nil {
+			t.Fatalf("cleanPollOptions(%q): expected no error, got %v", test.options, err)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Fatalf("cleanPollOptions(%q): expected %q, got %q", test.options, test.expected, got)
+		}
+		if status != test.want {
+			t.Fatalf("cleanPollOptions(%q): expected status %q, got %q", test.options, test.want, status)
+		}
+	}
+
+	_, _, err := cfg.cleanPollOptions([]string{"yes", "blorp"}, chirpStatusPublished)
+	if !errors.Is(err, errChirpRejected) {
+		t.Fatalf("cleanPollOptions with a rejected term: expected errChirpRejected, got %v", err)
+	}
+
+	// Masking a short term lengthens the option past the limit.
+	long := strings.Repeat("a", maxPollOptionLength-4) + " zap"
+	_, _, err = cfg.cleanPollOptions([]string{long, "no"}, chirpStatusPublished)
+	if err == nil {
+		t.Fatalf("cleanPollOptions(%q): expected error, got no error", long)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageCursor marks the last item of a page. It is handed to clients as an
+// opaque string and only ever compared against (created_at, id) in SQL, or
+// (rank, id) for search results.
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Rank      *float64  `json:"r,omitempty"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	dat, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(dat)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	cursor := pageCursor{}
+	dat, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(dat, &cursor)
+	if err != nil {
+		return cursor, err
+	}
+	if cursor.ID == uuid.Nil || cursor.CreatedAt.IsZero() {
+		return cursor, fmt.Errorf("incomplete cursor")
+	}
+	return cursor, nil
+}
+
+// cursorParams converts an optional cursor into the nullable arguments taken
+// by the keyset queries.
+func cursorParams(cursor *pageCursor) (sql.NullTime, uuid.NullUUID) {
+	if cursor == nil {
+		return sql.NullTime{}, uuid.NullUUID{}
+	}
+	return sql.NullTime{Time: cursor.CreatedAt, Valid: true}, uuid.NullUUID{UUID: cursor.ID, Valid: true}
+}
+
+type pageParams struct {
+	Limit  int32
+	Cursor *pageCursor
+}
+
+// parsePageParams reads the `limit` and `cursor` query parameters shared by
+// every paginated endpoint.
+func parsePageParams(r *http.Request) (pageParams, error) {
+	params := pageParams{Limit: defaultPageLimit}
+
+	limit := r.URL.Query().Get("limit")
+	if limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			return params, fmt.Errorf("limit must be a positive integer")
+		}
+		if parsed > maxPageLimit {
+			parsed = maxPageLimit
+		}
+		params.Limit = int32(parsed)
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		parsed, err := decodeCursor(cursor)
+		if err != nil {
+			return params, fmt.Errorf("invalid cursor: %w", err)
+		}
+		params.Cursor = &parsed
+	}
+
+	return params, nil
+}
+
+// setNextLink advertises the next page through a Link header that keeps all
+// other query parameters of the current request.
+func setNextLink(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+	query := r.URL.Query()
+	query.Set("cursor", nextCursor)
+	w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, query.Encode()))
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	rank := 0.0607927
+	tests := []pageCursor{
+		{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.UTC), ID: uuid.New()},
+		{CreatedAt: time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC), ID: uuid.New(), Rank: &rank},
+	}
+
+	for _, cursor := range tests {
+		got, err := decodeCursor(encodeCursor(cursor))
+		if err != nil {
+			t.Fatalf("decodeCursor: expected no error, got %v", err)
+		}
+		if !got.CreatedAt.Equal(cursor.CreatedAt) || got.ID != cursor.ID {
+			t.Fatalf("expected %v %v, got %v %v", cursor.CreatedAt, cursor.ID, got.CreatedAt, got.ID)
+		}
+		if (got.Rank == nil) != (cursor.Rank == nil) {
+			t.Fatalf("expected rank %v, got %v", cursor.Rank, got.Rank)
+		}
+		if got.Rank != nil && *got.Rank != *cursor.Rank {
+			t.Fatalf("expected rank %v, got %v", *cursor.Rank, *got.Rank)
+		}
+	}
+}
+
+func TestDecodeCursorMalformed(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	tests := []string{
+		"",
+		"not base64!",
+		encode("not json"),
+		encode("{}"),
+		encode(`{"t":"2025-01-02T03:04:05Z"}`),
+		encode(`{"id":"` + uuid.NewString() + `"}`),
+		encode(`{"t":"2025-01-02T03:04:05Z","id":"00000000-0000-0000-0000-000000000000"}`),
+		encode(`{"t":"yesterday","id":"` + uuid.NewString() + `"}`),
+		encode(`{"t":"2025-01-02T03:04:05Z","id":"not-a-uuid"}`),
+	}
+
+	for _, input := range tests {
+		_, err := decodeCursor(input)
+		if err == nil {
+			t.Fatalf("decodeCursor(%q): expected error, got no error", input)
+		}
+	}
+}
+
+func TestParsePageParams(t *testing.T) {
+	cursor := pageCursor{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), ID: uuid.New()}
+	tests := []struct {
+		query      string
+		limit      int32
+		withCursor bool
+		wantErr    bool
+	}{
+		{query: "", limit: defaultPageLimit},
+		{query: "limit=1", limit: 1},
+		{query: "limit=100", limit: maxPageLimit},
+		{query: "limit=101", limit: maxPageLimit},
+		{query: "limit=100000", limit: maxPageLimit},
+		{query: "limit=0", wantErr: true},
+		{query: "limit=-5", wantErr: true},
+		{query: "limit=ten", wantErr: true},
+		{query: "cursor=" + encodeCursor(cursor), limit: defaultPageLimit, withCursor: true},
+		{query: "limit=5&cursor=" + encodeCursor(cursor), limit: 5, withCursor: true},
+		{query: "cursor=garbage", wantErr: true},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/api/chirps?"+test.query, nil)
+		got, err := parsePageParams(r)
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("parsePageParams(%q): expected error, got no error", test.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("parsePageParams(%q): expected no error, got %v", test.query, err)
+		}
+		if got.Limit != test.limit {
+			t.Fatalf("parsePageParams(%q): expected limit %d, got %d", test.query, test.limit, got.Limit)
+		}
+		if (got.Cursor != nil) != test.withCursor {
+			t.Fatalf("parsePageParams(%q): expected cursor %v, got %v", test.query, test.withCursor, got.Cursor)
+		}
+		if got.Cursor != nil && got.Cursor.ID != cursor.ID {
+			t.Fatalf("parsePageParams(%q): expected cursor ID %v, got %v", test.query, cursor.ID, got.Cursor.ID)
+		}
+	}
+}
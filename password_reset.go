@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jradziejewski/chirpy/internal/auth"
+	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/jradziejewski/chirpy/internal/mailer"
+)
+
+const (
+	passwordResetTTL = 30 * time.Minute
+
+	// passwordResetInterval keeps the forgot endpoint from being used to
+	// flood an inbox.
+	passwordResetInterval = time.Minute
+
+	// Forgot requests are unauthenticated, so they are rate limited per
+	// client and per email, then served by a fixed number of workers from a
+	// bounded queue.
+	passwordResetWorkers   = 4
+	passwordResetQueueSize = 64
+
+	passwordResetIPLimit     = 10
+	passwordResetEmailLimit  = 3
+	passwordResetLimitPeriod = 15 * time.Minute
+)
+
+// queuePasswordReset hands email to the password reset workers. It never
+// blocks, and reports whether the request was queued.
+func (cfg *apiConfig) queuePasswordReset(email string) bool {
+	select {
+	case cfg.passwordResets <- email:
+		return true
+	default:
+		return false
+	}
+}
+
+// runPasswordResetWorker serves queued password resets until ctx is
+// cancelled. main starts passwordResetWorkers of them.
+func (cfg *apiConfig) runPasswordResetWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case email := <-cfg.passwordResets:
+			err := cfg.startPasswordReset(ctx, email)
+			if err != nil {
+				log.Printf("Error starting password reset: %s", err)
+			}
+		}
+	}
+}
+
+// startPasswordReset emails a reset token to the account registered under
+// email, if there is one. Only a hash of the token is stored, and sending a
+// new token invalidates the ones sent before.
+func (cfg *apiConfig) startPasswordReset(ctx context.Context, email string) error {
+	user, err := cfg.db.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	latest, err := cfg.db.GetLatestPasswordReset(ctx, user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil && time.Since(latest.CreatedAt) < passwordResetInterval {
+		return nil
+	}
+
+	token, err := auth.MakeRefreshToken()
+	if err != nil {
+		return err
+	}
+
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.DeleteUnusedPasswordResets(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	err = qtx.CreatePasswordReset(ctx, database.CreatePasswordResetParams{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(passwordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return cfg.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password for this account. If it was you, send this token with your new password to POST /api/password/reset:\r\n\r\n%s\r\n\r\nThe token expires in %d minutes. If you did not ask for a reset, you can ignore this email.",
+			token,
+			int(passwordResetTTL.Minutes()),
+		),
+	})
+}
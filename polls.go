@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+	defaultPollMinutes  = 24 * 60
+)
+
+// pollParameters is the poll section of a create chirp request.
+type pollParameters struct {
+	Options         []string `json:"options"`
+	DurationMinutes *int     `json:"duration_minutes"`
+}
+
+// newPoll is a validated poll waiting for its chirp to be created.
+type newPoll struct {
+	Options  []string
+	ClosesAt time.Time
+}
+
+func validatePoll(params pollParameters, now time.Time) (newPoll, error) {
+	if len(params.Options) < minPollOptions || len(params.Options) > maxPollOptions {
+		return newPoll{}, fmt.Errorf("A poll must have between %d and %d options", minPollOptions, maxPollOptions)
+	}
+
+	options := make([]string, 0, len(params.Options))
+	seen := map[string]bool{}
+	for _, option := range params.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return newPoll{}, errors.New("Poll options cannot be empty")
+		}
+		if len([]rune(option)) > maxPollOptionLength {
+			return newPoll{}, fmt.Errorf("Poll options cannot be longer than %d characters", maxPollOptionLength)
+		}
+		if seen[strings.ToLower(option)] {
+			return newPoll{}, errors.New("Poll options must be distinct")
+		}
+		seen[strings.ToLower(option)] = true
+		options = append(options, option)
+	}
+
+	minutes := defaultPollMinutes
+	if params.DurationMinutes != nil {
+		minutes = *params.DurationMinutes
+	}
+	duration := time.Duration(minutes) * time.Minute
+	if duration < minPollDuration || duration > maxPollDuration {
+		return newPoll{}, fmt.Errorf("duration_minutes must be between %d and %d", int(minPollDuration.Minutes()), int(maxPollDuration.Minutes()))
+	}
+
+	return newPoll{
+		Options:  options,
+		ClosesAt: now.Add(duration),
+	}, nil
+}
+
+// createPoll attaches a poll to a freshly created chirp, inside the same
+// transaction.
+func createPoll(ctx context.Context, q *database.Queries, chirp database.Chirp, poll newPoll) error {
+	created, err := q.CreatePoll(ctx, database.CreatePollParams{
+		ID:        uuid.New(),
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		ClosesAt:  poll.ClosesAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, label := range poll.Options {
+		err = q.CreatePollOption(ctx, database.CreatePollOptionParams{
+			ID:       uuid.New(),
+			PollID:   created.ID,
+			Position: int32(i),
+			Label:    label,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type PollResponse struct {
+	ID       uuid.UUID            `json:"id"`
+	ClosesAt time.Time            `json:"closes_at"`
+	Closed   bool                 `json:"closed"`
+	Options  []PollOptionResponse `json:"options"`
+	// Results are only shown once the viewer has voted or the poll has
+	// closed, so TotalVotes and each option's Votes are omitted until then.
+	TotalVotes    *int64     `json:"total_votes,omitempty"`
+	VotedOptionID *uuid.UUID `json:"voted_option_id,omitempty"`
+}
+
+type PollOptionResponse struct {
+	ID    uuid.UUID `json:"id"`
+	Label string    `json:"label"`
+	Votes *int64    `json:"votes,omitempty"`
+}
+
+// loadPolls returns the polls attached to chirpIDs keyed by chirp ID, as
+// seen by viewerID.
+func (cfg *apiConfig) loadPolls(ctx context.Context, chirpIDs []uuid.UUID, viewerID uuid.NullUUID) (map[uuid.UUID]*PollResponse, error) {
+	polls, err := cfg.db.GetPollsByChirpIDs(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	responses := make(map[uuid.UUID]*PollResponse, len(polls))
+	if len(polls) == 0 {
+		return responses, nil
+	}
+
+	pollIDs := make([]uuid.UUID, 0, len(polls))
+	for _, poll := range polls {
+		pollIDs = append(pollIDs, poll.ID)
+	}
+
+	options, err := cfg.db.GetPollOptionsWithVotes(ctx, pollIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	votedOptions := map[uuid.UUID]uuid.UUID{}
+	if viewerID.Valid {
+		votes, err := cfg.db.GetPollVotesByUser(ctx, database.GetPollVotesByUserParams{
+			UserID:  viewerID.UUID,
+			PollIds: pollIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, vote := range votes {
+			votedOptions[vote.PollID] = vote.OptionID
+		}
+	}
+
+	now := time.Now().UTC()
+	byPollID := make(map[uuid.UUID]*PollResponse, len(polls))
+	for _, poll := range polls {
+		resp := &PollResponse{
+			ID:       poll.ID,
+			ClosesAt: poll.ClosesAt,
+			Closed:   !now.Before(poll.ClosesAt),
+			Options:  []PollOptionResponse{},
+		}
+		if optionID, ok := votedOptions[poll.ID]; ok {
+			resp.VotedOptionID = &optionID
+		}
+		if resp.Closed || resp.VotedOptionID != nil {
+			total := int64(0)
+			resp.TotalVotes = &total
+		}
+		responses[poll.ChirpID] = resp
+		byPollID[poll.ID] = resp
+	}
+
+	for _, option := range options {
+		resp := byPollID[option.PollID]
+		optionResp := PollOptionResponse{
+			ID:    option.ID,
+			Label: option.Label,
+		}
+		if resp.TotalVotes != nil {
+			votes := option.Votes
+			optionResp.Votes = &votes
+			*resp.TotalVotes += votes
+		}
+		resp.Options = append(resp.Options, optionResp)
+	}
+
+	return responses, nil
+}
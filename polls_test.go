@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidatePoll(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	minutes := func(n int) *int {
+		return &n
+	}
+	tests := []struct {
+		params   pollParameters
+		options  []string
+		closesAt time.Time
+	}{
+		{
+			params:   pollParameters{Options: []string{"Yes", "No"}},
+			options:  []string{"Yes", "No"},
+			closesAt: now.Add(24 * time.Hour),
+		},
+		{
+			params:   pollParameters{Options: []string{"  cats ", "dogs", "birds", "fish"}, DurationMinutes: minutes(5)},
+			options:  []string{"cats", "dogs", "birds", "fish"},
+			closesAt: now.Add(5 * time.Minute),
+		},
+		{
+			params:   pollParameters{Options: []string{strings.Repeat("é", 25), "b"}, DurationMinutes: minutes(7 * 24 * 60)},
+			options:  []string{strings.Repeat("é", 25), "b"},
+			closesAt: now.Add(7 * 24 * time.Hour),
+		},
+	}
+
+	for _, test := range tests {
+		got, err := validatePoll(test.params, now)
+		if err != nil {
+			t.Fatalf("validatePoll(%v): expected no error, got %v", test.params.Options, err)
+		}
+		if !reflect.DeepEqual(got.Options, test.options) {
+			t.Fatalf("validatePoll(%v): expected options %q, got %q", test.params.Options, test.options, got.Options)
+		}
+		if !got.ClosesAt.Equal(test.closesAt) {
+			t.Fatalf("validatePoll(%v): expected closes_at %v, got %v", test.params.Options, test.closesAt, got.ClosesAt)
+		}
+	}
+}
+
+func TestValidatePollInvalid(t *testing.T) {
+	now := time.Now()
+	minutes := func(n int) *int {
+		return &n
+	}
+	tests := []pollParameters{
+		{Options: nil},
+		{Options: []string{"only"}},
+		{Options: []string{"a", "b", "c", "d", "e"}},
+		{Options: []string{"a", "  "}},
+		{Options: []string{"a", strings.Repeat("b", 26)}},
+		{Options: []string{"Yes", "yes"}},
+		{Options: []string{"a", "b"}, DurationMinutes: minutes(4)},
+		{Options: []string{"a", "b"}, DurationMinutes: minutes(7*24*60 + 1)},
+		{Options: []string{"a", "b"}, DurationMinutes: minutes(-10)},
+	}
+
+	for _, params := range tests {
+		_, err := validatePoll(params, now)
+		if err == nil {
+			t.Fatalf("validatePoll(%v): expected error, got no error", params)
+		}
+	}
+}
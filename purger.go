@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// chirpRestoreWindow is how long a deleted chirp can be restored before
+	// it is purged.
+	chirpRestoreWindow = 30 * 24 * time.Hour
+	purgeInterval      = time.Hour
+)
+
+// runPurger purges chirps whose restore window has passed until ctx is
+// cancelled.
+func (cfg *apiConfig) runPurger(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := cfg.purgeDeletedChirps(ctx)
+		if err != nil {
+			log.Printf("Error purging deleted chirps: %s", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted chirps", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeDeletedChirps hard-deletes chirps whose restore window has passed.
+// A chirp that still has replies is kept as a body-less tombstone instead,
+// so its replies stay attached to the thread; it is purged once they are
+// gone. Attachments and earlier revisions go either way.
+func (cfg *apiConfig) purgeDeletedChirps(ctx context.Context) (int64, error) {
+	before := time.Now().UTC().Add(-chirpRestoreWindow)
+
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	attachments, err := qtx.DeleteAttachmentsOfDeletedChirps(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	err = qtx.DeleteRevisionsOfDeletedChirps(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	// Purging a deleted reply can leave its deleted parent without replies,
+	// so repeat until a whole chain of deleted chirps is gone.
+	var purged int64
+	for {
+		n, err := qtx.PurgeDeletedChirps(ctx, before)
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			break
+		}
+		purged += n
+	}
+
+	_, err = qtx.StripDeletedChirps(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	objects := make([]string, 0, 2*len(attachments))
+	for _, attachment := range attachments {
+		objects = append(objects, attachment.FileName, attachment.ThumbnailName)
+	}
+	err = cfg.deleteUnreferencedMedia(ctx, objects)
+	if err != nil {
+		log.Printf("Error deleting media of purged chirps: %s", err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+const (
+	publishInterval  = 15 * time.Second
+	publishBatchSize = 50
+)
+
+// runPublisher publishes due scheduled chirps until ctx is cancelled. Every
+// server instance runs one; ClaimDueScheduledChirps keeps them from
+// publishing the same chirp twice.
+func (cfg *apiConfig) runPublisher(ctx context.Context) {
+	ticker := time.NewTicker(publishInterval)
+	defer ticker.Stop()
+
+	for {
+		published, err := cfg.publishDueChirps(ctx)
+		if err != nil {
+			log.Printf("Error publishing scheduled chirps: %s", err)
+		}
+		// A full batch means more may be waiting, so go again right away.
+		if err == nil && published == publishBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDueChirps turns one batch of due scheduled chirps into chirps. The
+// claimed rows stay locked until the transaction commits, so a crash halfway
+// through leaves them to be picked up again. Each chirp is published under
+// its own savepoint: one that fails is marked failed and the rest of the
+// batch still goes out.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) (int, error) {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	due, err := qtx.ClaimDueScheduledChirps(ctx, database.ClaimDueScheduledChirpsParams{
+		Now:   time.Now().UTC(),
+		Limit: publishBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, scheduled := range due {
+		_, err = tx.ExecContext(ctx, "savepoint publish_scheduled_chirp")
+		if err != nil {
+			return 0, err
+		}
+
+		publishErr := cfg.publishScheduledChirp(ctx, qtx, scheduled)
+		if publishErr != nil {
+			log.Printf("Error publishing scheduled chirp %s: %s", scheduled.ID, publishErr)
+
+			_, err = tx.ExecContext(ctx, "rollback to savepoint publish_scheduled_chirp")
+			if err != nil {
+				return 0, err
+			}
+			now := time.Now().UTC()
+			err = qtx.FailScheduledChirp(ctx, database.FailScheduledChirpParams{
+				FailedAt: sql.NullTime{Time: now, Valid: true},
+				Failure:  sql.NullString{String: "Could not publish chirp", Valid: true},
+				ID:       scheduled.ID,
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "release savepoint publish_scheduled_chirp")
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return len(due), nil
+}
+
+// publishScheduledChirp creates the chirp for scheduled and removes it from
+// the schedule.
+func (cfg *apiConfig) publishScheduledChirp(ctx context.Context, qtx *database.Queries, scheduled database.ScheduledChirp) error {
+	// Replied-to and quoted chirps that have been deleted since scheduling,
+	// or are no longer visible to the author, are dropped so the chirp goes
+	// out standalone.
+	chirp := newChirp{
+		UserID:     scheduled.UserID,
+		Body:       scheduled.Body,
+		InReplyTo:  scheduled.InReplyTo,
+		QuoteOf:    scheduled.QuoteOf,
+		Visibility: scheduled.Visibility,
+	}
+	// The rules may have changed since scheduling. Nobody is waiting for a
+	// 422, so a chirp that would now be rejected is held for review instead.
+	var err error
+	chirp.Body, chirp.Status, err = cfg.cleanChirpBody(scheduled.Body)
+	if errors.Is(err, errChirpRejected) {
+		chirp.Body, chirp.Status = scheduled.Body, chirpStatusHeld
+	} else if err != nil {
+		return err
+	}
+
+	_, err = resolveChirpReferences(ctx, qtx, chirp.UserID, chirp.InReplyTo, uuid.NullUUID{})
+	if errors.Is(err, errParentNotFound) {
+		chirp.InReplyTo = uuid.NullUUID{}
+	} else if err != nil {
+		return err
+	}
+	_, err = resolveChirpReferences(ctx, qtx, chirp.UserID, uuid.NullUUID{}, chirp.QuoteOf)
+	if errors.Is(err, errQuotedNotFound) {
+		chirp.QuoteOf = uuid.NullUUID{}
+	} else if err != nil {
+		return err
+	}
+
+	_, err = createChirp(ctx, qtx, chirp)
+	if err != nil {
+		return err
+	}
+
+	_, err = qtx.DeleteScheduledChirp(ctx, scheduled.ID)
+	return err
+}
@@ -0,0 +1,28 @@
+-- name: CreateBlock :exec
+insert into blocks (blocker_id, blocked_id, created_at)
+values (
+	$1,
+	$2,
+	$3
+)
+on conflict do nothing;
+
+-- name: DeleteBlock :exec
+delete from blocks
+where blocker_id = $1 and blocked_id = $2;
+
+-- name: HasBlocked :one
+select exists (
+	select 1 from blocks
+	where blocker_id = $1 and blocked_id = $2
+);
+
+-- name: GetBlocks :many
+select * from blocks
+where blocker_id = sqlc.arg('user_id')
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, blocked_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+order by created_at desc, blocked_id desc
+limit sqlc.arg('limit');
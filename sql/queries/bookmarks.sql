@@ -0,0 +1,34 @@
+-- name: CreateBookmark :exec
+insert into bookmarks (user_id, chirp_id, created_at)
+values (
+	$1,
+	$2,
+	$3
+)
+on conflict do nothing;
+
+-- name: DeleteBookmark :exec
+delete from bookmarks
+where user_id = $1 and chirp_id = $2;
+
+-- name: GetBookmarkedChirpIDs :many
+select chirp_id from bookmarks
+where user_id = sqlc.arg('user_id')
+and chirp_id = any(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetBookmarks :many
+select
+	sqlc.embed(chirps),
+	bookmarks.created_at as bookmarked_at
+from bookmarks
+inner join chirps on chirps.id = bookmarks.chirp_id
+where bookmarks.user_id = sqlc.arg('user_id')
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (bookmarks.created_at, bookmarks.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+and chirps.deleted_at is null
+and chirps.status = 'published'
+and chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.arg('user_id'))
+order by bookmarks.created_at desc, bookmarks.chirp_id desc
+limit sqlc.arg('limit');
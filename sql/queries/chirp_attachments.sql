@@ -0,0 +1,71 @@
+-- name: CreateChirpAttachment :exec
+insert into chirp_attachments (
+	id,
+	chirp_id,
+	position,
+	file_name,
+	thumbnail_name,
+	content_type,
+	width,
+	height,
+	thumbnail_width,
+	thumbnail_height,
+	alt_text,
+	created_at
+)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8,
+	$9,
+	$10,
+	$11,
+	$12
+);
+
+-- name: GetChirpAttachments :many
+select * from chirp_attachments
+where chirp_id = any(sqlc.arg('chirp_ids')::uuid[])
+order by chirp_id, position;
+
+-- name: GetUserMediaObjects :many
+-- Names of every stored object the user's attachments use, originals and
+-- thumbnails.
+select file_name as name from chirp_attachments
+join chirps on chirps.id = chirp_attachments.chirp_id
+where chirps.user_id = $1
+union
+select thumbnail_name as name from chirp_attachments
+join chirps on chirps.id = chirp_attachments.chirp_id
+where chirps.user_id = $1;
+
+-- name: CountMediaObjectReferences :one
+select count(*) from chirp_attachments
+where file_name = sqlc.arg('name')
+or thumbnail_name = sqlc.arg('name');
+
+-- name: GetVisibleMediaObject :one
+-- Returns the visibility of a chirp the viewer may see that uses the object,
+-- preferring a public one. No row means the viewer may not fetch it.
+select chirps.visibility from chirp_attachments
+join chirps on chirps.id = chirp_attachments.chirp_id
+where (chirp_attachments.file_name = sqlc.arg('name') or chirp_attachments.thumbnail_name = sqlc.arg('name'))
+and chirps.deleted_at is null
+and chirps.status = 'published'
+and chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.narg('viewer_id')::uuid)
+order by chirps.visibility = 'public' desc
+limit 1;
+
+-- name: DeleteAttachmentsOfDeletedChirps :many
+-- Returns the objects the removed attachments used, so unreferenced ones
+-- can be deleted from storage.
+delete from chirp_attachments
+using chirps
+where chirps.id = chirp_attachments.chirp_id
+and chirps.deleted_at < sqlc.arg('before')::timestamp
+returning chirp_attachments.file_name, chirp_attachments.thumbnail_name;
@@ -0,0 +1,21 @@
+-- name: CreateChirpLike :exec
+insert into chirp_likes (chirp_id, user_id, created_at)
+values (
+	$1,
+	$2,
+	$3
+)
+on conflict do nothing;
+
+-- name: DeleteChirpLike :exec
+delete from chirp_likes
+where chirp_id = $1 and user_id = $2;
+
+-- name: GetChirpLikeStats :many
+select
+	chirp_id,
+	count(*) as like_count,
+	coalesce(bool_or(user_id = sqlc.narg('viewer_id')::uuid), false)::bool as liked_by_viewer
+from chirp_likes
+where chirp_id = any(sqlc.arg('chirp_ids')::uuid[])
+group by chirp_id;
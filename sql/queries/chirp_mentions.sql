@@ -0,0 +1,49 @@
+-- name: CreateChirpMention :exec
+insert into chirp_mentions (chirp_id, user_id, start_offset, length, created_at)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5
+);
+
+-- name: DeleteChirpMentions :exec
+delete from chirp_mentions
+where chirp_id = $1;
+
+-- name: GetChirpMentions :many
+select
+	chirp_mentions.chirp_id,
+	chirp_mentions.user_id,
+	chirp_mentions.start_offset,
+	chirp_mentions.length,
+	users.username
+from chirp_mentions
+inner join users on users.id = chirp_mentions.user_id
+where chirp_mentions.chirp_id = any(sqlc.arg('chirp_ids')::uuid[])
+order by chirp_mentions.chirp_id, chirp_mentions.start_offset;
+
+-- name: GetMentioningChirps :many
+-- Mentions by users the mentioned user has blocked or muted are left out.
+select * from chirps
+where exists (
+	select 1 from chirp_mentions
+	where chirp_mentions.chirp_id = chirps.id
+	and chirp_mentions.user_id = sqlc.arg('user_id')
+)
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, sqlc.arg('user_id'))
+and not author_muted_by(user_id, sqlc.arg('user_id'))
+and not exists (
+	select 1 from blocks
+	where blocks.blocker_id = sqlc.arg('user_id')
+	and blocks.blocked_id = chirps.user_id
+)
+order by created_at desc, id desc
+limit sqlc.arg('limit');
@@ -0,0 +1,28 @@
+-- name: CreateChirpRevision :one
+insert into chirp_revisions (id, chirp_id, body, created_at, replaced_at)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5
+)
+returning *;
+
+-- name: GetChirpRevisions :many
+select * from chirp_revisions
+where chirp_id = $1
+order by replaced_at;
+
+-- name: GetChirpRevisionsByUser :many
+-- Earlier bodies of every chirp the user wrote, for data exports.
+select chirp_revisions.* from chirp_revisions
+join chirps on chirps.id = chirp_revisions.chirp_id
+where chirps.user_id = $1
+order by chirp_revisions.chirp_id, chirp_revisions.replaced_at;
+
+-- name: DeleteRevisionsOfDeletedChirps :exec
+delete from chirp_revisions
+using chirps
+where chirps.id = chirp_revisions.chirp_id
+and chirps.deleted_at < sqlc.arg('before')::timestamp;
@@ -1,26 +1,213 @@
 -- name: CreateChirp :one
-insert into chirps (id, created_at, updated_at, body, user_id)
+insert into chirps (id, created_at, updated_at, body, user_id, in_reply_to, root_id, rechirp_of, quote_of, is_quote, visibility, status)
 values (
 	$1,
 	$2,
 	$3,
 	$4,
-	$5
+	$5,
+	$6,
+	$7,
+	$8,
+	$9,
+	$10,
+	$11,
+	$12
 )
 returning *;
 
--- name: GetChirps :many
+-- name: GetChirpsAsc :many
+-- Muted authors are left out unless the listing asks for that author.
+-- exclude_id leaves out a chirp listed elsewhere, such as a pinned one.
 select * from chirps
-where coalesce(sqlc.narg('user_id'), user_id) is null or user_id =coalesce(sqlc.narg('user_id'), user_id) 
-order by created_at;
+where (sqlc.narg('user_id')::uuid is null or user_id = sqlc.narg('user_id')::uuid)
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid)
+and (sqlc.narg('user_id')::uuid is not null or not author_muted_by(user_id, sqlc.narg('viewer_id')::uuid))
+and (sqlc.narg('exclude_id')::uuid is null or id <> sqlc.narg('exclude_id')::uuid)
+order by created_at, id
+limit sqlc.arg('limit');
+
+-- name: GetChirpsDesc :many
+-- Muted authors are left out unless the listing asks for that author.
+-- exclude_id leaves out a chirp listed elsewhere, such as a pinned one.
+select * from chirps
+where (sqlc.narg('user_id')::uuid is null or user_id = sqlc.narg('user_id')::uuid)
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid)
+and (sqlc.narg('user_id')::uuid is not null or not author_muted_by(user_id, sqlc.narg('viewer_id')::uuid))
+and (sqlc.narg('exclude_id')::uuid is null or id <> sqlc.narg('exclude_id')::uuid)
+order by created_at desc, id desc
+limit sqlc.arg('limit');
 
 -- name: GetChirp :one
 select * from chirps
 where id = $1;
 
+-- name: GetVisibleChirp :one
+-- Reads a chirp on behalf of a viewer. Chirps the viewer may not see are
+-- reported as missing so their existence is not leaked.
+select * from chirps
+where id = sqlc.arg('id')
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid);
+
+-- name: GetChirpsByIDs :many
+-- Deleted chirps are included so quotes of them can be shown as tombstones.
+select * from chirps
+where id = any(sqlc.arg('ids')::uuid[])
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid);
+
+-- name: GetVisibleChirpIDs :many
+-- Narrows ids down to the chirps the viewer may read.
+select id from chirps
+where id = any(sqlc.arg('ids')::uuid[])
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid);
+
+-- name: GetRechirp :one
+select * from chirps
+where user_id = $1 and rechirp_of = $2;
+
+-- name: HoldChirp :exec
+update chirps
+set status = 'held'
+where id = $1
+and status = 'published';
+
+-- name: GetChirpForUpdate :one
+select * from chirps
+where id = $1
+for update;
+
+-- name: UpdateChirpBody :one
+update chirps
+set body = $1, updated_at = $2
+where id = $3
+returning *;
+
+-- name: DeleteRechirp :exec
+delete from chirps
+where user_id = $1 and rechirp_of = $2;
+
 -- name: DeleteChirps :exec
 delete from chirps;
 
--- name: DeleteChirp :exec
+-- name: SoftDeleteChirp :exec
+-- Rechirps of a deleted chirp are hidden along with it.
+update chirps
+set deleted_at = sqlc.arg('deleted_at')::timestamp
+where (id = sqlc.arg('id') or rechirp_of = sqlc.arg('id'))
+and deleted_at is null;
+
+-- name: RestoreChirp :exec
+-- Brings back a deleted chirp and the rechirps that were deleted with it.
+update chirps
+set deleted_at = null
+where (id = sqlc.arg('id') or rechirp_of = sqlc.arg('id'))
+and deleted_at = sqlc.arg('deleted_at')::timestamp;
+
+-- name: PurgeDeletedChirps :execrows
+-- Chirps that still have replies are kept as tombstones, so the thread
+-- below them stays in one piece; see StripDeletedChirps.
 delete from chirps
-where id = $1;
+where deleted_at < sqlc.arg('before')::timestamp
+and not exists (
+	select 1 from chirps replies
+	where replies.in_reply_to = chirps.id
+	or replies.root_id = chirps.id
+);
+
+-- name: StripDeletedChirps :execrows
+-- Empties the body of deleted chirps that are kept as tombstones.
+update chirps
+set body = ''
+where deleted_at < sqlc.arg('before')::timestamp
+and body <> '';
+
+-- name: SearchChirps :many
+-- Muted authors are left out unless the listing asks for that author.
+-- The snippet is HTML: the body is escaped before the matches are wrapped
+-- in <mark>, so it is safe to render as markup.
+select
+	sqlc.embed(chirps),
+	ts_rank(search_vector, to_tsquery('english', sqlc.arg('query')))::float8 as rank,
+	ts_headline(
+		'english',
+		replace(replace(replace(replace(replace(body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+		to_tsquery('english', sqlc.arg('query')),
+		'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'
+	)::text as snippet
+from chirps
+where search_vector @@ to_tsquery('english', sqlc.arg('query'))
+and (sqlc.narg('user_id')::uuid is null or user_id = sqlc.narg('user_id')::uuid)
+and (
+	sqlc.narg('cursor_rank')::float8 is null
+	or (ts_rank(search_vector, to_tsquery('english', sqlc.arg('query')))::float8, id)
+		< (sqlc.narg('cursor_rank')::float8, sqlc.narg('cursor_id')::uuid)
+)
+and deleted_at is null
+and status = 'published'
+and chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid)
+and (sqlc.narg('user_id')::uuid is not null or not author_muted_by(user_id, sqlc.narg('viewer_id')::uuid))
+order by rank desc, id desc
+limit sqlc.arg('limit');
+
+-- name: GetChirpThread :many
+-- Deleted chirps are kept so their replies stay attached to the thread.
+with recursive thread(id, depth) as (
+	select id, 0 from chirps
+	where id = sqlc.arg('root_id')
+	and status = 'published'
+	and chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id')::uuid)
+	union all
+	select c.id, t.depth + 1 from chirps c
+	inner join thread t on c.in_reply_to = t.id
+	where c.status = 'published'
+	and chirp_visible_to(c.id, c.user_id, c.visibility, sqlc.narg('viewer_id')::uuid)
+)
+select sqlc.embed(chirps), thread.depth
+from thread
+inner join chirps on chirps.id = thread.id
+order by thread.depth, chirps.created_at, chirps.id;
+
+-- name: GetTimeline :many
+select chirps.* from chirps
+inner join follows on follows.followee_id = chirps.user_id
+where follows.follower_id = sqlc.arg('user_id')
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+and chirps.deleted_at is null
+and chirps.status = 'published'
+and chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.arg('user_id'))
+and not author_muted_by(chirps.user_id, sqlc.arg('user_id'))
+order by chirps.created_at desc, chirps.id desc
+limit sqlc.arg('limit');
+
+-- name: UpdateChirpStatus :exec
+-- Rechirps follow the status of the chirp they share.
+update chirps
+set status = $1
+where id = $2 or rechirp_of = $2;
+
+-- name: GetAllChirpsByUser :many
+-- Every chirp the user ever wrote, including deleted and moderated ones, for
+-- data exports.
+select * from chirps
+where user_id = $1
+order by created_at, id;
@@ -0,0 +1,48 @@
+-- name: CreateDraft :one
+insert into drafts (id, created_at, updated_at, user_id, body, in_reply_to, quote_of, visibility)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8
+)
+returning *;
+
+-- name: GetDraft :one
+select * from drafts
+where id = $1 and user_id = $2;
+
+-- name: GetDraftForUpdate :one
+select * from drafts
+where id = $1 and user_id = $2
+for update;
+
+-- name: GetDrafts :many
+select * from drafts
+where user_id = sqlc.arg('user_id')
+and (
+	sqlc.narg('cursor_updated_at')::timestamp is null
+	or (updated_at, id) < (sqlc.narg('cursor_updated_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+order by updated_at desc, id desc
+limit sqlc.arg('limit');
+
+-- name: UpdateDraft :one
+update drafts
+set body = $1, in_reply_to = $2, quote_of = $3, visibility = $4, updated_at = $5
+where id = $6 and user_id = $7
+returning *;
+
+-- name: DeleteDraft :execrows
+delete from drafts
+where id = $1 and user_id = $2;
+
+-- name: GetAllDraftsByUser :many
+-- Every draft of the user, for data exports.
+select * from drafts
+where user_id = $1
+order by created_at, id;
@@ -0,0 +1,32 @@
+-- name: CreateEmailVerification :one
+insert into email_verifications (id, user_id, email, created_at, expires_at)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5
+)
+returning *;
+
+-- name: GetEmailVerificationForUpdate :one
+select * from email_verifications
+where id = $1
+for update;
+
+-- name: GetLatestEmailVerification :one
+select * from email_verifications
+where user_id = $1
+order by created_at desc
+limit 1;
+
+-- name: UseEmailVerification :exec
+update email_verifications
+set used_at = $1
+where id = $2;
+
+-- name: DeleteUnusedEmailVerifications :exec
+-- Only the most recently sent link should work.
+delete from email_verifications
+where user_id = $1
+and used_at is null;
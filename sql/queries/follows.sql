@@ -0,0 +1,37 @@
+-- name: CreateFollow :exec
+insert into follows (follower_id, followee_id, created_at)
+values (
+	$1,
+	$2,
+	$3
+)
+on conflict do nothing;
+
+-- name: DeleteFollow :exec
+delete from follows
+where follower_id = $1 and followee_id = $2;
+
+-- name: GetFollowers :many
+select * from follows
+where followee_id = sqlc.arg('user_id')
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, follower_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+order by created_at desc, follower_id desc
+limit sqlc.arg('limit');
+
+-- name: GetFollowing :many
+select * from follows
+where follower_id = sqlc.arg('user_id')
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, followee_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+order by created_at desc, followee_id desc
+limit sqlc.arg('limit');
+
+-- name: DeleteFollowsBetween :exec
+delete from follows
+where (follower_id = $1 and followee_id = $2)
+or (follower_id = $2 and followee_id = $1);
@@ -0,0 +1,57 @@
+-- name: UpsertHashtag :one
+insert into hashtags (id, name, created_at)
+values (
+	$1,
+	$2,
+	$3
+)
+on conflict (name) do update set name = excluded.name
+returning *;
+
+-- name: CreateChirpHashtag :exec
+insert into chirp_hashtags (chirp_id, hashtag_id, created_at)
+values (
+	$1,
+	$2,
+	$3
+)
+on conflict do nothing;
+
+-- name: DeleteChirpHashtags :exec
+delete from chirp_hashtags
+where chirp_id = $1;
+
+-- name: GetHashtagChirps :many
+select chirps.* from chirps
+inner join chirp_hashtags on chirp_hashtags.chirp_id = chirps.id
+inner join hashtags on hashtags.id = chirp_hashtags.hashtag_id
+where hashtags.name = sqlc.arg('name')
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+and chirps.deleted_at is null
+and chirps.status = 'published'
+and chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.narg('viewer_id')::uuid)
+and not author_muted_by(chirps.user_id, sqlc.narg('viewer_id')::uuid)
+order by chirps.created_at desc, chirps.id desc
+limit sqlc.arg('limit');
+
+-- name: GetTrendingHashtags :many
+-- Every use inside the window scores 1 / (1 + age in hours), so recent
+-- bursts outrank tags that were popular earlier in the window. Only public
+-- chirps count, so trends never reveal restricted chirps.
+select
+	hashtags.name,
+	count(*) as uses,
+	sum(1.0 / (1.0 + extract(epoch from (sqlc.arg('now')::timestamp - chirp_hashtags.created_at)) / 3600.0))::float8 as score
+from chirp_hashtags
+inner join hashtags on hashtags.id = chirp_hashtags.hashtag_id
+inner join chirps on chirps.id = chirp_hashtags.chirp_id
+where chirp_hashtags.created_at > sqlc.arg('since')::timestamp
+and chirps.visibility = 'public'
+and chirps.deleted_at is null
+and chirps.status = 'published'
+group by hashtags.name
+order by score desc, hashtags.name
+limit sqlc.arg('limit');
@@ -0,0 +1,23 @@
+-- name: CreateLoginChallenge :exec
+insert into login_challenges (id, user_id, created_at, expires_at)
+values (
+	$1,
+	$2,
+	$3,
+	$4
+);
+
+-- name: GetLoginChallengeForUpdate :one
+select * from login_challenges
+where id = $1
+for update;
+
+-- name: RecordLoginChallengeAttempt :exec
+update login_challenges
+set attempts = attempts + 1
+where id = $1;
+
+-- name: UseLoginChallenge :exec
+update login_challenges
+set used_at = $1
+where id = $2;
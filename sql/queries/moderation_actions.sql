@@ -0,0 +1,21 @@
+-- name: CreateModerationAction :one
+insert into moderation_actions (id, created_at, moderator_id, action, chirp_id, target_user_id, note)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7
+)
+returning *;
+
+-- name: GetModerationActions :many
+select * from moderation_actions
+where (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+order by created_at desc, id desc
+limit sqlc.arg('limit');
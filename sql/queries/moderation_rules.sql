@@ -0,0 +1,20 @@
+-- name: GetModerationRules :many
+select * from moderation_rules
+order by term;
+
+-- name: UpsertModerationRule :one
+insert into moderation_rules (id, term, action, created_at, updated_at)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5
+)
+on conflict (term) do update
+set action = excluded.action, updated_at = excluded.updated_at
+returning *;
+
+-- name: DeleteModerationRule :execrows
+delete from moderation_rules
+where id = $1;
@@ -0,0 +1,22 @@
+-- name: CreateMute :exec
+insert into mutes (muter_id, muted_id, created_at)
+values (
+	$1,
+	$2,
+	$3
+)
+on conflict do nothing;
+
+-- name: DeleteMute :exec
+delete from mutes
+where muter_id = $1 and muted_id = $2;
+
+-- name: GetMutes :many
+select * from mutes
+where muter_id = sqlc.arg('user_id')
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, muted_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+order by created_at desc, muted_id desc
+limit sqlc.arg('limit');
@@ -0,0 +1,29 @@
+-- name: CreatePasswordReset :exec
+insert into password_resets (token_hash, user_id, created_at, expires_at)
+values (
+	$1,
+	$2,
+	$3,
+	$4
+);
+
+-- name: GetPasswordResetForUpdate :one
+select * from password_resets
+where token_hash = $1
+for update;
+
+-- name: GetLatestPasswordReset :one
+select * from password_resets
+where user_id = $1
+order by created_at desc
+limit 1;
+
+-- name: UsePasswordReset :exec
+update password_resets
+set used_at = $1
+where token_hash = $2;
+
+-- name: DeleteUnusedPasswordResets :exec
+delete from password_resets
+where user_id = $1
+and used_at is null;
@@ -0,0 +1,52 @@
+-- name: CreatePoll :one
+insert into polls (id, chirp_id, created_at, closes_at)
+values (
+	$1,
+	$2,
+	$3,
+	$4
+)
+returning *;
+
+-- name: CreatePollOption :exec
+insert into poll_options (id, poll_id, position, label)
+values (
+	$1,
+	$2,
+	$3,
+	$4
+);
+
+-- name: CreatePollVote :exec
+insert into poll_votes (poll_id, user_id, option_id, created_at)
+values (
+	$1,
+	$2,
+	$3,
+	$4
+);
+
+-- name: GetPollByChirpID :one
+select * from polls
+where chirp_id = $1;
+
+-- name: GetPollsByChirpIDs :many
+select * from polls
+where chirp_id = any(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetPollOptionsWithVotes :many
+select
+	poll_options.id,
+	poll_options.poll_id,
+	poll_options.label,
+	count(poll_votes.user_id) as votes
+from poll_options
+left join poll_votes on poll_votes.option_id = poll_options.id
+where poll_options.poll_id = any(sqlc.arg('poll_ids')::uuid[])
+group by poll_options.id
+order by poll_options.poll_id, poll_options.position;
+
+-- name: GetPollVotesByUser :many
+select * from poll_votes
+where user_id = sqlc.arg('user_id')
+and poll_id = any(sqlc.arg('poll_ids')::uuid[]);
@@ -0,0 +1,18 @@
+-- name: CreateRecoveryCode :exec
+insert into recovery_codes (code_hash, user_id, created_at)
+values (
+	$1,
+	$2,
+	$3
+);
+
+-- name: DeleteRecoveryCodes :exec
+delete from recovery_codes
+where user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+update recovery_codes
+set used_at = $1
+where user_id = $2
+and code_hash = $3
+and used_at is null;
@@ -25,3 +25,14 @@ where token = $1;
 update refresh_tokens
 set revoked_at = $1, updated_at = $1
 where token = $2;
+
+-- name: RevokeUserTokens :exec
+update refresh_tokens
+set revoked_at = $1, updated_at = $1
+where user_id = $2
+and revoked_at is null;
+
+-- name: GetUserRefreshTokens :many
+select * from refresh_tokens
+where user_id = $1
+order by created_at;
@@ -0,0 +1,44 @@
+-- name: CreateReport :one
+insert into reports (id, created_at, updated_at, chirp_id, reporter_id, reason, details)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7
+)
+returning *;
+
+-- name: GetOpenReportsForChirps :many
+select * from reports
+where chirp_id = any(sqlc.arg('chirp_ids')::uuid[])
+and status = 'open'
+order by created_at, id;
+
+-- name: CloseChirpReports :execrows
+update reports
+set status = sqlc.arg('status'), updated_at = sqlc.arg('updated_at')
+where chirp_id = sqlc.arg('chirp_id')
+and status = 'open';
+
+-- name: GetModerationQueue :many
+-- The queue holds every live chirp that is held by the moderation pipeline
+-- or has open reports, oldest first.
+select * from chirps
+where deleted_at is null
+and (
+	status = 'held'
+	or exists (
+		select 1 from reports
+		where reports.chirp_id = chirps.id
+		and reports.status = 'open'
+	)
+)
+and (
+	sqlc.narg('cursor_created_at')::timestamp is null
+	or (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+order by created_at, id
+limit sqlc.arg('limit');
@@ -0,0 +1,56 @@
+-- name: CreateScheduledChirp :one
+insert into scheduled_chirps (id, created_at, updated_at, user_id, body, in_reply_to, quote_of, publish_at, visibility)
+values (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8,
+	$9
+)
+returning *;
+
+-- name: GetScheduledChirp :one
+select * from scheduled_chirps
+where id = $1;
+
+-- name: GetScheduledChirps :many
+select * from scheduled_chirps
+where user_id = sqlc.arg('user_id')
+and (
+	sqlc.narg('cursor_publish_at')::timestamp is null
+	or (publish_at, id) > (sqlc.narg('cursor_publish_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+order by publish_at, id
+limit sqlc.arg('limit');
+
+-- name: ClaimDueScheduledChirps :many
+-- Rows locked by another publisher are skipped rather than waited on, so
+-- several server instances can publish concurrently without duplicates.
+-- Chirps of suspended users and chirps that failed to publish are left
+-- unpublished.
+select * from scheduled_chirps
+where publish_at <= sqlc.arg('now')
+and failed_at is null
+and user_id not in (select id from users where suspended_at is not null)
+order by publish_at, id
+limit sqlc.arg('limit')
+for update skip locked;
+
+-- name: DeleteScheduledChirp :execrows
+delete from scheduled_chirps
+where id = $1;
+
+-- name: FailScheduledChirp :exec
+update scheduled_chirps
+set failed_at = $1, failure = $2, updated_at = $1
+where id = $3;
+
+-- name: GetAllScheduledChirpsByUser :many
+-- Every scheduled chirp of the user, including failed ones, for data exports.
+select * from scheduled_chirps
+where user_id = $1
+order by publish_at, id;
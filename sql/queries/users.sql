@@ -1,14 +1,19 @@
 -- name: CreateUser :one
-insert into users (id, created_at, updated_at, email, hashed_password)
+insert into users (id, created_at, updated_at, email, hashed_password, username)
 values (
 	$1,
 	$2,
 	$3,
 	$4,
-	$5
+	$5,
+	$6
 )
 returning *;
 
+-- name: GetUser :one
+select * from users
+where id = $1;
+
 -- name: GetUserByEmail :one
 select * from users
 where email = $1;
@@ -22,8 +27,13 @@ and expires_at > NOW()
 and revoked_at is null;
 
 -- name: UpdateEmailAndPassword :one
+-- Changing the email address marks it unverified again.
 update users
-set email = $1, hashed_password = $2, updated_at = NOW()
+set
+	email = $1,
+	hashed_password = $2,
+	email_verified_at = case when email = $1 then email_verified_at end,
+	updated_at = NOW()
 where id = $3
 returning *;
 
@@ -35,3 +45,84 @@ returning *;
 
 -- name: DeleteUsers :exec
 DELETE FROM users;
+
+-- name: GetUsersByUsernames :many
+select * from users
+where lower(username) = any(sqlc.arg('usernames')::text[]);
+
+-- name: UpdateProfile :one
+update users
+set
+	username = coalesce(sqlc.narg('username'), username),
+	display_name = coalesce(sqlc.narg('display_name'), display_name),
+	bio = coalesce(sqlc.narg('bio'), bio),
+	updated_at = NOW()
+where id = sqlc.arg('id')
+returning *;
+
+-- name: GetUserByUsername :one
+select * from users
+where lower(username) = lower(sqlc.arg('username'));
+
+-- name: GetUsersByIDs :many
+select * from users
+where id = any(sqlc.arg('ids')::uuid[]);
+
+-- name: UpdatePinnedChirp :one
+update users
+set pinned_chirp_id = $1, updated_at = NOW()
+where id = $2
+returning *;
+
+-- name: ClearPinnedChirp :exec
+-- Unpins a deleted chirp, and the rechirps deleted with it, from every
+-- profile. Soft deletes do not fire the foreign key's on delete set null.
+update users
+set pinned_chirp_id = null, updated_at = NOW()
+where pinned_chirp_id in (
+	select id from chirps
+	where id = $1 or rechirp_of = $1
+);
+
+-- name: SuspendUser :exec
+update users
+set suspended_at = $1, updated_at = $1
+where id = $2;
+
+-- name: VerifyUserEmail :exec
+update users
+set email_verified_at = $1, updated_at = $1
+where id = $2;
+
+-- name: UpdatePassword :exec
+update users
+set hashed_password = $1, updated_at = NOW()
+where id = $2;
+
+-- name: DeleteUser :execrows
+-- Everything the user owns goes with them through on delete cascade.
+delete from users
+where id = $1;
+
+-- name: SetTOTPSecret :exec
+-- Starts (or restarts) enrollment. 2FA stays off until EnableTOTP.
+update users
+set totp_secret = $1, totp_enabled_at = null, totp_last_step = null, updated_at = NOW()
+where id = $2;
+
+-- name: EnableTOTP :exec
+update users
+set totp_enabled_at = $1, totp_last_step = $2, updated_at = $1
+where id = $3;
+
+-- name: DisableTOTP :exec
+update users
+set totp_secret = null, totp_enabled_at = null, totp_last_step = null, updated_at = NOW()
+where id = $1;
+
+-- name: UpdateTOTPLastStep :execrows
+-- Only moves forward, so one code cannot complete two logins.
+update users
+set totp_last_step = sqlc.arg('step')
+where id = sqlc.arg('id')
+and (totp_last_step is null or totp_last_step < sqlc.arg('step'));
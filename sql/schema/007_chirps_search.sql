@@ -0,0 +1,11 @@
+-- +goose Up
+alter table chirps
+add search_vector tsvector generated always as (to_tsvector('english', body)) stored;
+
+create index chirps_search_vector_idx on chirps using gin (search_vector);
+
+-- +goose Down
+drop index chirps_search_vector_idx;
+
+alter table chirps
+drop search_vector;
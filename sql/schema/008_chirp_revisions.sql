@@ -0,0 +1,14 @@
+-- +goose Up
+create table chirp_revisions(
+	id uuid primary key,
+	chirp_id uuid not null,
+	body text not null,
+	created_at timestamp not null,
+	replaced_at timestamp not null,
+	foreign key (chirp_id) references chirps(id) on delete cascade
+);
+
+create index chirp_revisions_chirp_id_idx on chirp_revisions (chirp_id, replaced_at);
+
+-- +goose Down
+drop table chirp_revisions;
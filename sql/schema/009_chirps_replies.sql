@@ -0,0 +1,12 @@
+-- +goose Up
+alter table chirps
+add in_reply_to uuid references chirps(id) on delete set null,
+add root_id uuid references chirps(id) on delete set null;
+
+create index chirps_in_reply_to_idx on chirps (in_reply_to);
+create index chirps_root_id_idx on chirps (root_id);
+
+-- +goose Down
+alter table chirps
+drop in_reply_to,
+drop root_id;
@@ -0,0 +1,15 @@
+-- +goose Up
+create table follows(
+	follower_id uuid not null,
+	followee_id uuid not null,
+	created_at timestamp not null,
+	primary key (follower_id, followee_id),
+	foreign key (follower_id) references users(id) on delete cascade,
+	foreign key (followee_id) references users(id) on delete cascade,
+	check (follower_id <> followee_id)
+);
+
+create index follows_followee_id_idx on follows (followee_id, created_at);
+
+-- +goose Down
+drop table follows;
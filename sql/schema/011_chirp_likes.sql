@@ -0,0 +1,14 @@
+-- +goose Up
+create table chirp_likes(
+	chirp_id uuid not null,
+	user_id uuid not null,
+	created_at timestamp not null,
+	primary key (chirp_id, user_id),
+	foreign key (chirp_id) references chirps(id) on delete cascade,
+	foreign key (user_id) references users(id) on delete cascade
+);
+
+create index chirp_likes_user_id_idx on chirp_likes (user_id);
+
+-- +goose Down
+drop table chirp_likes;
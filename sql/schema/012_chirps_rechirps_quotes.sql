@@ -0,0 +1,25 @@
+-- +goose Up
+-- Rechirps have no body of their own, so bodies can no longer be unique.
+alter table chirps
+drop constraint chirps_body_key;
+
+alter table chirps
+add rechirp_of uuid references chirps(id) on delete cascade,
+add quote_of uuid references chirps(id) on delete set null,
+add is_quote boolean not null default false;
+
+create unique index chirps_user_id_rechirp_of_idx on chirps (user_id, rechirp_of)
+where rechirp_of is not null;
+create index chirps_quote_of_idx on chirps (quote_of);
+
+-- +goose Down
+drop index chirps_quote_of_idx;
+drop index chirps_user_id_rechirp_of_idx;
+
+alter table chirps
+drop rechirp_of,
+drop quote_of,
+drop is_quote;
+
+alter table chirps
+add constraint chirps_body_key unique (body);
@@ -0,0 +1,22 @@
+-- +goose Up
+create table hashtags(
+	id uuid primary key,
+	name text unique not null,
+	created_at timestamp not null
+);
+
+create table chirp_hashtags(
+	chirp_id uuid not null,
+	hashtag_id uuid not null,
+	created_at timestamp not null,
+	primary key (chirp_id, hashtag_id),
+	foreign key (chirp_id) references chirps(id) on delete cascade,
+	foreign key (hashtag_id) references hashtags(id) on delete cascade
+);
+
+create index chirp_hashtags_hashtag_id_idx on chirp_hashtags (hashtag_id, created_at);
+create index chirp_hashtags_created_at_idx on chirp_hashtags (created_at);
+
+-- +goose Down
+drop table chirp_hashtags;
+drop table hashtags;
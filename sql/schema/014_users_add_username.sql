@@ -0,0 +1,11 @@
+-- +goose Up
+alter table users
+add username text;
+
+create unique index users_username_lower_idx on users (lower(username));
+
+-- +goose Down
+drop index users_username_lower_idx;
+
+alter table users
+drop username;
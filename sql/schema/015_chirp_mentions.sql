@@ -0,0 +1,16 @@
+-- +goose Up
+create table chirp_mentions(
+	chirp_id uuid not null,
+	user_id uuid not null,
+	start_offset integer not null,
+	length integer not null,
+	created_at timestamp not null,
+	primary key (chirp_id, start_offset),
+	foreign key (chirp_id) references chirps(id) on delete cascade,
+	foreign key (user_id) references users(id) on delete cascade
+);
+
+create index chirp_mentions_user_id_idx on chirp_mentions (user_id, created_at);
+
+-- +goose Down
+drop table chirp_mentions;
@@ -0,0 +1,9 @@
+-- +goose Up
+alter table users
+add display_name text not null default '',
+add bio text not null default '';
+
+-- +goose Down
+alter table users
+drop display_name,
+drop bio;
@@ -0,0 +1,20 @@
+-- +goose Up
+create table chirp_attachments(
+	id uuid primary key,
+	chirp_id uuid not null,
+	position integer not null,
+	file_name text not null,
+	thumbnail_name text not null,
+	content_type text not null,
+	width integer not null,
+	height integer not null,
+	thumbnail_width integer not null,
+	thumbnail_height integer not null,
+	alt_text text not null default '',
+	created_at timestamp not null,
+	unique (chirp_id, position),
+	foreign key (chirp_id) references chirps(id) on delete cascade
+);
+
+-- +goose Down
+drop table chirp_attachments;
@@ -0,0 +1,18 @@
+-- +goose Up
+create table scheduled_chirps(
+	id uuid primary key,
+	created_at timestamp not null,
+	updated_at timestamp not null,
+	user_id uuid not null,
+	body text not null,
+	in_reply_to uuid references chirps(id) on delete set null,
+	quote_of uuid references chirps(id) on delete set null,
+	publish_at timestamp not null,
+	foreign key (user_id) references users(id) on delete cascade
+);
+
+create index scheduled_chirps_publish_at_idx on scheduled_chirps (publish_at);
+create index scheduled_chirps_user_id_idx on scheduled_chirps (user_id, publish_at, id);
+
+-- +goose Down
+drop table scheduled_chirps;
@@ -0,0 +1,16 @@
+-- +goose Up
+create table drafts(
+	id uuid primary key,
+	created_at timestamp not null,
+	updated_at timestamp not null,
+	user_id uuid not null,
+	body text not null,
+	in_reply_to uuid references chirps(id) on delete set null,
+	quote_of uuid references chirps(id) on delete set null,
+	foreign key (user_id) references users(id) on delete cascade
+);
+
+create index drafts_user_id_idx on drafts (user_id, updated_at, id);
+
+-- +goose Down
+drop table drafts;
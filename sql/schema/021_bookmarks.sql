@@ -0,0 +1,14 @@
+-- +goose Up
+create table bookmarks(
+	user_id uuid not null,
+	chirp_id uuid not null,
+	created_at timestamp not null,
+	primary key (user_id, chirp_id),
+	foreign key (user_id) references users(id) on delete cascade,
+	foreign key (chirp_id) references chirps(id) on delete cascade
+);
+
+create index bookmarks_user_id_created_at_idx on bookmarks (user_id, created_at, chirp_id);
+
+-- +goose Down
+drop table bookmarks;
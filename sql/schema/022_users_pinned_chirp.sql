@@ -0,0 +1,7 @@
+-- +goose Up
+alter table users
+add pinned_chirp_id uuid references chirps(id) on delete set null;
+
+-- +goose Down
+alter table users
+drop pinned_chirp_id;
@@ -0,0 +1,49 @@
+-- +goose Up
+alter table chirps
+add visibility text not null default 'public'
+	check (visibility in ('public', 'followers', 'mentioned'));
+
+alter table scheduled_chirps
+add visibility text not null default 'public'
+	check (visibility in ('public', 'followers', 'mentioned'));
+
+alter table drafts
+add visibility text not null default 'public'
+	check (visibility in ('public', 'followers', 'mentioned'));
+
+-- chirp_visible_to is the single place that decides who can read a chirp.
+-- Authors always see their own chirps, followers-only chirps are shown to
+-- followers and mentioned-only chirps to the users they mention. A null
+-- viewer only sees public chirps.
+-- +goose StatementBegin
+create function chirp_visible_to(chirp_id uuid, author_id uuid, chirp_visibility text, viewer_id uuid)
+returns boolean
+language sql
+stable
+as $$
+	select chirp_visibility = 'public'
+		or author_id = viewer_id
+		or (chirp_visibility = 'followers' and exists (
+			select 1 from follows
+			where follows.follower_id = viewer_id
+			and follows.followee_id = author_id
+		))
+		or (chirp_visibility = 'mentioned' and exists (
+			select 1 from chirp_mentions
+			where chirp_mentions.chirp_id = chirp_visible_to.chirp_id
+			and chirp_mentions.user_id = viewer_id
+		));
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+drop function chirp_visible_to;
+
+alter table drafts
+drop visibility;
+
+alter table scheduled_chirps
+drop visibility;
+
+alter table chirps
+drop visibility;
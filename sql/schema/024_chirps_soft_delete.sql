@@ -0,0 +1,12 @@
+-- +goose Up
+alter table chirps
+add deleted_at timestamp;
+
+create index chirps_deleted_at_idx on chirps (deleted_at)
+where deleted_at is not null;
+
+-- +goose Down
+drop index chirps_deleted_at_idx;
+
+alter table chirps
+drop deleted_at;
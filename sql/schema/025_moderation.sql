@@ -0,0 +1,31 @@
+-- +goose Up
+create table moderation_rules (
+	id uuid primary key,
+	term text not null unique,
+	action text not null check (action in ('mask', 'hold', 'reject')),
+	created_at timestamp not null,
+	updated_at timestamp not null
+);
+
+-- The words replaceProfane used to mask.
+insert into moderation_rules (id, term, action, created_at, updated_at)
+values
+	(gen_random_uuid(), 'kerfuffle', 'mask', now(), now()),
+	(gen_random_uuid(), 'sharbert', 'mask', now(), now()),
+	(gen_random_uuid(), 'fornax', 'mask', now(), now());
+
+alter table users
+add is_admin boolean not null default false;
+
+alter table chirps
+add status text not null default 'published'
+	check (status in ('published', 'held'));
+
+-- +goose Down
+alter table chirps
+drop status;
+
+alter table users
+drop is_admin;
+
+drop table moderation_rules;
@@ -0,0 +1,51 @@
+-- +goose Up
+create table reports (
+	id uuid primary key,
+	created_at timestamp not null,
+	updated_at timestamp not null,
+	chirp_id uuid not null references chirps(id) on delete cascade,
+	reporter_id uuid not null references users(id) on delete cascade,
+	reason text not null
+		check (reason in ('spam', 'harassment', 'hate', 'violence', 'sexual', 'misinformation', 'other')),
+	details text not null default '',
+	status text not null default 'open'
+		check (status in ('open', 'resolved', 'dismissed')),
+	unique (chirp_id, reporter_id)
+);
+
+create index reports_open_chirp_id_idx on reports (chirp_id) where status = 'open';
+
+create table moderation_actions (
+	id uuid primary key,
+	created_at timestamp not null,
+	moderator_id uuid references users(id) on delete set null,
+	action text not null check (action in ('resolve', 'dismiss', 'hide', 'suspend')),
+	chirp_id uuid references chirps(id) on delete set null,
+	target_user_id uuid references users(id) on delete set null,
+	note text not null default ''
+);
+
+create index moderation_actions_created_at_idx on moderation_actions (created_at, id);
+
+alter table users
+add suspended_at timestamp;
+
+alter table chirps
+drop constraint chirps_status_check,
+add constraint chirps_status_check check (status in ('published', 'held', 'hidden'));
+
+-- +goose Down
+update chirps
+set status = 'held'
+where status = 'hidden';
+
+alter table chirps
+drop constraint chirps_status_check,
+add constraint chirps_status_check check (status in ('published', 'held'));
+
+alter table users
+drop suspended_at;
+
+drop table moderation_actions;
+
+drop table reports;
@@ -0,0 +1,95 @@
+-- +goose Up
+create table blocks(
+	blocker_id uuid not null,
+	blocked_id uuid not null,
+	created_at timestamp not null,
+	primary key (blocker_id, blocked_id),
+	foreign key (blocker_id) references users(id) on delete cascade,
+	foreign key (blocked_id) references users(id) on delete cascade,
+	check (blocker_id <> blocked_id)
+);
+
+create index blocks_blocked_id_idx on blocks (blocked_id);
+
+create table mutes(
+	muter_id uuid not null,
+	muted_id uuid not null,
+	created_at timestamp not null,
+	primary key (muter_id, muted_id),
+	foreign key (muter_id) references users(id) on delete cascade,
+	foreign key (muted_id) references users(id) on delete cascade,
+	check (muter_id <> muted_id)
+);
+
+-- Blocked users see none of the blocker's chirps, whatever their visibility.
+-- +goose StatementBegin
+create or replace function chirp_visible_to(chirp_id uuid, author_id uuid, chirp_visibility text, viewer_id uuid)
+returns boolean
+language sql
+stable
+as $$
+	select not exists (
+			select 1 from blocks
+			where blocks.blocker_id = author_id
+			and blocks.blocked_id = viewer_id
+		)
+		and (
+			chirp_visibility = 'public'
+			or author_id = viewer_id
+			or (chirp_visibility = 'followers' and exists (
+				select 1 from follows
+				where follows.follower_id = viewer_id
+				and follows.followee_id = author_id
+			))
+			or (chirp_visibility = 'mentioned' and exists (
+				select 1 from chirp_mentions
+				where chirp_mentions.chirp_id = chirp_visible_to.chirp_id
+				and chirp_mentions.user_id = viewer_id
+			))
+		);
+$$;
+-- +goose StatementEnd
+
+-- author_muted_by decides whether listings shown to viewer leave out chirps
+-- by author. A null viewer has muted nobody.
+-- +goose StatementBegin
+create function author_muted_by(author_id uuid, viewer_id uuid)
+returns boolean
+language sql
+stable
+as $$
+	select exists (
+		select 1 from mutes
+		where mutes.muter_id = viewer_id
+		and mutes.muted_id = author_id
+	);
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+drop function author_muted_by;
+
+-- +goose StatementBegin
+create or replace function chirp_visible_to(chirp_id uuid, author_id uuid, chirp_visibility text, viewer_id uuid)
+returns boolean
+language sql
+stable
+as $$
+	select chirp_visibility = 'public'
+		or author_id = viewer_id
+		or (chirp_visibility = 'followers' and exists (
+			select 1 from follows
+			where follows.follower_id = viewer_id
+			and follows.followee_id = author_id
+		))
+		or (chirp_visibility = 'mentioned' and exists (
+			select 1 from chirp_mentions
+			where chirp_mentions.chirp_id = chirp_visible_to.chirp_id
+			and chirp_mentions.user_id = viewer_id
+		));
+$$;
+-- +goose StatementEnd
+
+drop table mutes;
+
+drop table blocks;
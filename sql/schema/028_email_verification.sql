@@ -0,0 +1,24 @@
+-- +goose Up
+alter table users
+add email_verified_at timestamp;
+
+-- Accounts created before verification existed keep working.
+update users
+set email_verified_at = created_at;
+
+create table email_verifications (
+	id uuid primary key,
+	user_id uuid not null references users(id) on delete cascade,
+	email text not null,
+	created_at timestamp not null,
+	expires_at timestamp not null,
+	used_at timestamp
+);
+
+create index email_verifications_user_id_idx on email_verifications (user_id, created_at);
+
+-- +goose Down
+drop table email_verifications;
+
+alter table users
+drop email_verified_at;
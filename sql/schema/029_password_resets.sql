@@ -0,0 +1,13 @@
+-- +goose Up
+create table password_resets (
+	token_hash text primary key,
+	user_id uuid not null references users(id) on delete cascade,
+	created_at timestamp not null,
+	expires_at timestamp not null,
+	used_at timestamp
+);
+
+create index password_resets_user_id_idx on password_resets (user_id, created_at);
+
+-- +goose Down
+drop table password_resets;
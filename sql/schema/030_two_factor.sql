@@ -0,0 +1,36 @@
+-- +goose Up
+-- totp_secret is set at enrollment; 2FA is only on once totp_enabled_at is
+-- set by confirming a first code. totp_last_step is the time step of the
+-- last accepted code, so codes cannot be replayed.
+alter table users
+add totp_secret text,
+add totp_enabled_at timestamp,
+add totp_last_step bigint;
+
+create table recovery_codes (
+	code_hash text primary key,
+	user_id uuid not null references users(id) on delete cascade,
+	created_at timestamp not null,
+	used_at timestamp
+);
+
+create index recovery_codes_user_id_idx on recovery_codes (user_id);
+
+create table login_challenges (
+	id uuid primary key,
+	user_id uuid not null references users(id) on delete cascade,
+	created_at timestamp not null,
+	expires_at timestamp not null,
+	attempts integer not null default 0,
+	used_at timestamp
+);
+
+-- +goose Down
+drop table login_challenges;
+
+drop table recovery_codes;
+
+alter table users
+drop totp_last_step,
+drop totp_enabled_at,
+drop totp_secret;
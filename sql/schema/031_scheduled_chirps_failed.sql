@@ -0,0 +1,11 @@
+-- +goose Up
+-- A scheduled chirp that cannot be published is marked failed and left for
+-- its author to see, instead of being retried forever.
+alter table scheduled_chirps
+add failed_at timestamp,
+add failure text;
+
+-- +goose Down
+alter table scheduled_chirps
+drop failure,
+drop failed_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Migration 012 dropped the unique constraint on chirp bodies because
+-- rechirps have no body of their own. Restore it for every other chirp.
+-- Image-only chirps and purged tombstones have empty bodies, and deleted
+-- chirps are left out so their text can be posted again, as it could when
+-- deletes were permanent.
+create unique index chirps_body_key on chirps (body)
+where rechirp_of is null
+and body <> ''
+and deleted_at is null;
+
+-- +goose Down
+drop index chirps_body_key;
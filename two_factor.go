@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/auth"
+	"github.com/jradziejewski/chirpy/internal/database"
+)
+
+const (
+	totpIssuer = "Chirpy"
+
+	recoveryCodeCount = 10
+
+	loginChallengePurpose = "login-challenge"
+	loginChallengeTTL     = 5 * time.Minute
+
+	// loginChallengeMaxAttempts bounds guessing: a six-digit code is only
+	// safe because a challenge allows so few tries.
+	loginChallengeMaxAttempts = 5
+)
+
+// createLoginChallenge starts the second step of a login for a user with
+// 2FA enabled. The returned token proves the password was right; it is
+// exchanged for a session at POST /api/login/2fa together with a code.
+func (cfg *apiConfig) createLoginChallenge(ctx context.Context, userID uuid.UUID) (string, error) {
+	token, payload, err := auth.MakeSignedToken(loginChallengePurpose, userID, cfg.secret, loginChallengeTTL)
+	if err != nil {
+		return "", err
+	}
+
+	err = cfg.db.CreateLoginChallenge(ctx, database.CreateLoginChallengeParams{
+		ID:        payload.ID,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: payload.ExpiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// createRecoveryCodes replaces the user's recovery codes with a fresh set
+// and returns them. Only their hashes are stored, so this is the one time
+// the codes can be shown.
+func (cfg *apiConfig) createRecoveryCodes(ctx context.Context, q *database.Queries, userID uuid.UUID) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.DeleteRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	for _, code := range codes {
+		err = q.CreateRecoveryCode(ctx, database.CreateRecoveryCodeParams{
+			CodeHash:  auth.HashToken(code),
+			UserID:    userID,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+// checkSecondFactor reports whether code is a current TOTP code or
+// recoveryCode an unused recovery code for user, and uses it up so it
+// cannot be presented again. It runs inside the caller's transaction.
+func (cfg *apiConfig) checkSecondFactor(ctx context.Context, q *database.Queries, user database.User, code, recoveryCode string) (bool, error) {
+	now := time.Now().UTC()
+
+	if code != "" {
+		step, ok := auth.ValidateTOTP(user.TotpSecret.String, code, now)
+		if !ok {
+			return false, nil
+		}
+		n, err := q.UpdateTOTPLastStep(ctx, database.UpdateTOTPLastStepParams{
+			Step: sql.NullInt64{Int64: step, Valid: true},
+			ID:   user.ID,
+		})
+		if err != nil {
+			return false, err
+		}
+		return n == 1, nil
+	}
+
+	if recoveryCode != "" {
+		n, err := q.UseRecoveryCode(ctx, database.UseRecoveryCodeParams{
+			UsedAt:   sql.NullTime{Time: now, Valid: true},
+			UserID:   user.ID,
+			CodeHash: auth.HashToken(auth.NormalizeRecoveryCode(recoveryCode)),
+		})
+		if err != nil {
+			return false, err
+		}
+		return n == 1, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{2,14}$`)
+
+// Usernames that would shadow routes under /api/users or impersonate staff.
+var reservedUsernames = map[string]bool{
+	"admin":     true,
+	"api":       true,
+	"chirpy":    true,
+	"me":        true,
+	"moderator": true,
+	"root":      true,
+	"support":   true,
+}
+
+// validateUsername enforces the public handle rules: 3-15 characters, ASCII
+// letters, digits and underscores, starting with a letter. Uniqueness is
+// case-insensitive and enforced by the database.
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("Username must be 3-15 letters, digits or underscores and start with a letter")
+	}
+	if reservedUsernames[strings.ToLower(username)] {
+		return fmt.Errorf("Username is reserved")
+	}
+	return nil
+}
+
+// validateEmail accepts a bare address such as "user@example.com", without a
+// display name or angle brackets.
+func validateEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return fmt.Errorf("Invalid email address")
+	}
+	return nil
+}
+
+func validateDisplayName(displayName string) error {
+	if utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+		return fmt.Errorf("Display name must be at most %d characters", maxDisplayNameLength)
+	}
+	return nil
+}
+
+func validateBio(bio string) error {
+	if utf8.RuneCountInString(bio) > maxBioLength {
+		return fmt.Errorf("Bio must be at most %d characters", maxBioLength)
+	}
+	return nil
+}
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
+	"net"
 	"net/http"
-	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/lib/pq"
 )
 
 func respondWithError(w http.ResponseWriter, code int, msg string, err error) {
@@ -36,22 +44,56 @@ func respondWithJson(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(dat)
 }
 
-func replaceProfane(text string) string {
-	words := strings.Fields(text)
-	var cleanWords []string
+// isUniqueViolation reports whether err was caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err was caused by a foreign key
+// constraint.
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+// indexChirp refreshes everything derived from a chirp body. It runs inside
+// the transaction that wrote the body.
+func indexChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	err := indexHashtags(ctx, q, chirp)
+	if err != nil {
+		return err
+	}
+
+	return indexMentions(ctx, q, chirp)
+}
 
-	for _, word := range words {
-		lower := strings.ToLower(word)
-		if lower == "kerfuffle" {
-			word = "****"
-		} else if lower == "sharbert" {
-			word = "****"
-		} else if lower == "fornax" {
-			word = "****"
-		}
+// parseOptionalUUID parses a form value that may be left empty.
+func parseOptionalUUID(value string) (uuid.NullUUID, error) {
+	if value == "" {
+		return uuid.NullUUID{}, nil
+	}
+	parsed, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: parsed, Valid: true}, nil
+}
 
-		cleanWords = append(cleanWords, word)
+// nullTimePtr turns a nullable timestamp into a pointer, which encodes as
+// JSON null when unset.
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
 	}
+	return &t.Time
+}
 
-	return strings.Join(cleanWords[:], " ")
+// clientIP returns the address the request came from, without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jradziejewski/chirpy/internal/auth"
+	"github.com/jradziejewski/chirpy/internal/database"
+	"github.com/jradziejewski/chirpy/internal/mailer"
+)
+
+const (
+	emailVerificationPurpose = "email-verification"
+	emailVerificationTTL     = 24 * time.Hour
+
+	// emailVerificationResendInterval keeps the resend endpoint from being
+	// used to flood an inbox.
+	emailVerificationResendInterval = time.Minute
+)
+
+// createEmailVerification issues a verification token for the user's
+// current email address and replaces any link sent before. It runs inside
+// the caller's transaction; the email itself goes out through
+// sendVerificationEmail once that transaction has committed.
+func (cfg *apiConfig) createEmailVerification(ctx context.Context, q *database.Queries, user database.User) (string, error) {
+	token, payload, err := auth.MakeSignedToken(emailVerificationPurpose, user.ID, cfg.secret, emailVerificationTTL)
+	if err != nil {
+		return "", err
+	}
+
+	err = q.DeleteUnusedEmailVerifications(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = q.CreateEmailVerification(ctx, database.CreateEmailVerificationParams{
+		ID:        payload.ID,
+		UserID:    user.ID,
+		Email:     user.Email,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: payload.ExpiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (cfg *apiConfig) sendVerificationEmail(ctx context.Context, email, token string) error {
+	return cfg.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your Chirpy email address",
+		Body: fmt.Sprintf(
+			"Welcome to Chirpy!\r\n\r\nTo verify your email address, send this token to POST /api/users/verify:\r\n\r\n%s\r\n\r\nThe token expires in %d hours.",
+			token,
+			int(emailVerificationTTL.Hours()),
+		),
+	})
+}